@@ -0,0 +1,40 @@
+// Command imdb-import builds Orbit's IMDb dataset from the TSV dumps at
+// https://datasets.imdbws.com/ (title.basics, title.ratings and
+// title.episode, gzipped or not).
+//
+//	imdb-import -dir ./imdb-dumps -out ./cache/imdb.gob.gz
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"github.com/unedtamps/orbit/internal/imdb"
+)
+
+func main() {
+	out := os.Getenv("IMDB_DATASET")
+	if out == "" {
+		out = "./cache/imdb.gob.gz"
+	}
+	dir := flag.String("dir", ".", "directory containing the IMDb TSV dumps")
+	flag.StringVar(&out, "out", out, "dataset file to write (defaults to $IMDB_DATASET)")
+	flag.Parse()
+
+	start := time.Now()
+	store, err := imdb.Import(*dir, func(file string, rows int) {
+		log.Printf("Read %d rows from %s", rows, file)
+	})
+	if err != nil {
+		log.Fatalf("Import failed: %v", err)
+	}
+	if err := store.Save(out); err != nil {
+		log.Fatalf("Failed to write %s: %v", out, err)
+	}
+
+	titles, ratings, episodes := store.Counts()
+	log.Printf("Wrote %s: %d titles, %d ratings, %d episodes in %s",
+		out, titles, ratings, episodes, time.Since(start).Round(time.Second))
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -17,6 +18,21 @@ type Config struct {
 	Timeout      time.Duration
 	StaticDir    string
 	TemplateGlob string
+	WatchRegion  string
+
+	ImageProxy      bool
+	ImageCacheDir   string
+	ImageCacheMaxMB int
+	ImageResize     bool
+
+	CertificationCountry string
+	MaxMovieRating       string
+	MaxTVRating          string
+
+	MetadataFallbacks   []string
+	MetadataSnapshotDir string
+
+	IMDbDataset string
 }
 
 func Load() (*Config, error) {
@@ -30,6 +46,21 @@ func Load() (*Config, error) {
 		Timeout:      getEnvDuration("PROXY_TIMEOUT", 30*time.Second),
 		StaticDir:    getEnv("STATIC_DIR", "./static"),
 		TemplateGlob: getEnv("TEMPLATE_GLOB", "templates/*.html"),
+		WatchRegion:  strings.ToUpper(getEnv("WATCH_REGION", "US")),
+
+		ImageProxy:      getEnvBool("IMAGE_PROXY", false),
+		ImageCacheDir:   getEnv("IMAGE_CACHE_DIR", "./cache/images"),
+		ImageCacheMaxMB: getEnvInt("IMAGE_CACHE_MAX_MB", 512),
+		ImageResize:     getEnvBool("IMAGE_RESIZE", false),
+
+		CertificationCountry: strings.ToUpper(getEnv("CERTIFICATION_COUNTRY", "US")),
+		MaxMovieRating:       getEnv("MAX_MOVIE_RATING", ""),
+		MaxTVRating:          getEnv("MAX_TV_RATING", ""),
+
+		MetadataFallbacks:   getEnvList("METADATA_FALLBACKS", "snapshot,tvmaze"),
+		MetadataSnapshotDir: getEnv("METADATA_SNAPSHOT_DIR", "./cache/metadata"),
+
+		IMDbDataset: getEnv("IMDB_DATASET", "./cache/imdb.gob.gz"),
 	}
 
 	if cfg.APIURL == "" {
@@ -38,9 +69,6 @@ func Load() (*Config, error) {
 	if cfg.APIKey == "" {
 		return nil, fmt.Errorf("API_KEY environment variable is required")
 	}
-	if cfg.TMDBAPIKey == "" {
-		return nil, fmt.Errorf("TMDB_API_KEY environment variable is required")
-	}
 
 	return cfg, nil
 }
@@ -52,6 +80,22 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+// getEnvList splits a comma-separated variable, dropping empty entries.
+// Setting the variable to "none" yields an empty list.
+func getEnvList(key, fallback string) []string {
+	v := getEnv(key, fallback)
+	if strings.EqualFold(v, "none") {
+		return nil
+	}
+	var out []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
 func getEnvInt(key string, fallback int) int {
 	if v := os.Getenv(key); v != "" {
 		if n, err := strconv.Atoi(v); err == nil {
@@ -61,6 +105,15 @@ func getEnvInt(key string, fallback int) int {
 	return fallback
 }
 
+func getEnvBool(key string, fallback bool) bool {
+	if v := os.Getenv(key); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+	return fallback
+}
+
 func getEnvDuration(key string, fallback time.Duration) time.Duration {
 	if v := os.Getenv(key); v != "" {
 		if d, err := time.ParseDuration(v); err == nil {
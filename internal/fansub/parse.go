@@ -0,0 +1,123 @@
+// Package fansub parses anime release names in the fansub style, such as
+// "[Group] Title - 13v2 (1080p) [ABCD1234].mkv", which number episodes
+// absolutely instead of with SxxEyy codes.
+package fansub
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Resolution is the quality fansub queries ask for first.
+const Resolution = "1080p"
+
+// Release is what a fansub release name says about itself.
+type Release struct {
+	Group string
+	Title string
+	// Season is 0 unless the title carries a season marker such as
+	// "S2" or "2nd Season".
+	Season int
+	// Episode is the first episode covered; LastEpisode is set for
+	// batches like "01-12" and equals Episode otherwise.
+	Episode     int
+	LastEpisode int
+	Version     int
+	Resolution  string
+}
+
+var (
+	groupRe      = regexp.MustCompile(`^\s*[\[【]([^\]】]+)[\]】]\s*`)
+	extensionRe  = regexp.MustCompile(`(?i)\.(mkv|mp4|avi)$`)
+	tagRe        = regexp.MustCompile(`\s*[\[(]([^\])]*)[\])]`)
+	resolutionRe = regexp.MustCompile(`(?i)\b(\d{3,4}p)\b|\b\d{3,4}x(\d{3,4})\b`)
+	// episodeRe matches "Title - 13", "Title - 13v2" and "Title - 01-12"
+	// or "Title - 01 ~ 12" at the end of what is left once tags are gone.
+	episodeRe = regexp.MustCompile(`^(.*?)\s+-\s+(\d{1,4})(?:v(\d))?(?:\s*[-~]\s*(\d{1,4})(?:v\d)?)?(?:\s+(?:END|Final))?\s*$`)
+	// batchRe matches "Title (01-12)", which has no dash before the range.
+	batchRe  = regexp.MustCompile(`\((\d{1,4})\s*[-~]\s*(\d{1,4})\)`)
+	seasonRe = regexp.MustCompile(`(?i)\s+(?:S(\d{1,2})|Season\s+(\d{1,2})|(\d{1,2})(?:st|nd|rd|th)\s+Season)$`)
+)
+
+// Parse reads a fansub release name. ok is false when the name has no
+// fansub-style episode number, which includes scene names using SxxEyy.
+func Parse(name string) (r Release, ok bool) {
+	name = extensionRe.ReplaceAllString(strings.TrimSpace(name), "")
+	if m := groupRe.FindStringSubmatch(name); m != nil {
+		r.Group = strings.TrimSpace(m[1])
+		name = name[len(m[0]):]
+	}
+
+	var first, last string
+	if m := batchRe.FindStringSubmatch(name); m != nil {
+		first, last = m[1], m[2]
+		name = strings.Replace(name, m[0], "", 1)
+	}
+
+	for _, m := range tagRe.FindAllStringSubmatch(name, -1) {
+		if res := resolutionRe.FindStringSubmatch(m[1]); res != nil && r.Resolution == "" {
+			r.Resolution = resolution(res)
+		}
+	}
+	name = strings.TrimSpace(tagRe.ReplaceAllString(name, ""))
+	if r.Resolution == "" {
+		if res := resolutionRe.FindStringSubmatch(name); res != nil {
+			r.Resolution = resolution(res)
+			name = strings.TrimSpace(strings.Replace(name, res[0], "", 1))
+		}
+	}
+
+	if m := episodeRe.FindStringSubmatch(name); m != nil {
+		name, first, last = m[1], m[2], m[4]
+		r.Version, _ = strconv.Atoi(m[3])
+	}
+	if first == "" {
+		return Release{}, false
+	}
+	r.Episode, _ = strconv.Atoi(first)
+	r.LastEpisode = r.Episode
+	if n, err := strconv.Atoi(last); err == nil && n > r.Episode {
+		r.LastEpisode = n
+	}
+
+	r.Title = strings.TrimSpace(name)
+	if m := seasonRe.FindStringSubmatch(r.Title); m != nil {
+		for _, s := range m[1:] {
+			if n, err := strconv.Atoi(s); err == nil {
+				r.Season = n
+				break
+			}
+		}
+		r.Title = strings.TrimSpace(r.Title[:len(r.Title)-len(m[0])])
+	}
+	return r, r.Title != ""
+}
+
+func resolution(m []string) string {
+	if m[1] != "" {
+		return strings.ToLower(m[1])
+	}
+	return m[2] + "p"
+}
+
+// Covers reports whether the release includes episode n.
+func (r Release) Covers(n int) bool {
+	return n > 0 && n >= r.Episode && n <= r.LastEpisode
+}
+
+// Batch reports whether the release spans several episodes.
+func (r Release) Batch() bool {
+	return r.LastEpisode > r.Episode
+}
+
+// Query is the fansub-style search for one episode, such as
+// "Title - 13 [1080p]". An empty resolution leaves the tag off.
+func Query(title string, episode int, resolution string) string {
+	q := fmt.Sprintf("%s - %02d", title, episode)
+	if resolution != "" {
+		q += " [" + resolution + "]"
+	}
+	return q
+}
@@ -2,10 +2,15 @@ package fetcher
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
 	"fmt"
 	"net/url"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/unedtamps/orbit/internal/model"
 
@@ -16,6 +21,10 @@ type Fetcher struct {
 	client *jackett.Client
 	apiURL string
 	apiKey string
+
+	mu              sync.Mutex
+	indexers        []Indexer
+	indexersFetched time.Time
 }
 
 func New(client *jackett.Client, apiURL, apiKey string) *Fetcher {
@@ -36,11 +45,12 @@ func (f *Fetcher) isJackettLink(link string) bool {
 	return strings.HasPrefix(link, f.apiURL)
 }
 
-// ToProxyURL converts a Jackett download URL to a proxy URL, hiding the API key.
+// ToProxyURL converts a Jackett download URL to a signed proxy URL, hiding
+// the API key.
 //
 // From: http://localhost:9117/dl/bitsearch/?jackett_apikey=...&path=...&file=...
-// To:   /dl/bitsearch?path=...&file=...
-func ToProxyURL(jackettURL string) string {
+// To:   /dl/bitsearch?path=...&file=...&sig=...
+func (f *Fetcher) ToProxyURL(jackettURL string) string {
 	if jackettURL == "" {
 		return ""
 	}
@@ -64,9 +74,24 @@ func ToProxyURL(jackettURL string) string {
 	if file != "" {
 		newQuery.Set("file", file)
 	}
+	newQuery.Set("sig", f.proxySignature(tracker, path, file))
 	return fmt.Sprintf("/dl/%s?%s", tracker, newQuery.Encode())
 }
 
+// proxySignature signs a proxy URL with the API key, so the proxy only
+// fetches downloads this server handed out rather than any Jackett path.
+func (f *Fetcher) proxySignature(tracker, path, file string) string {
+	mac := hmac.New(sha256.New, []byte(f.apiKey))
+	mac.Write([]byte(tracker + "\x00" + path + "\x00" + file))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// ValidProxySignature reports whether sig signs a proxy URL's tracker,
+// path and file.
+func (f *Fetcher) ValidProxySignature(tracker, path, file, sig string) bool {
+	return hmac.Equal([]byte(sig), []byte(f.proxySignature(tracker, path, file)))
+}
+
 // processResults converts Jackett API links to proxy URLs (hides API key)
 // and sorts by seeders desc, then peers desc.
 func (f *Fetcher) processResults(
@@ -83,7 +108,7 @@ func (f *Fetcher) processResults(
 		if !f.isJackettLink(results[i].Link) {
 			continue
 		}
-		results[i].Link = ToProxyURL(results[i].Link)
+		results[i].Link = f.ToProxyURL(results[i].Link)
 	}
 
 	sort.Slice(results, func(i, j int) bool {
@@ -150,6 +175,22 @@ func (f *Fetcher) FetchTV(ctx context.Context, query string) ([]jackett.Result,
 	return f.processResults(ctx, append(results, altResults...))
 }
 
+// FetchAnime searches the anime categories only. Anime trackers name
+// releases in the fansub style, which the rutor fallback never carries.
+func (f *Fetcher) FetchAnime(ctx context.Context, query string) ([]jackett.Result, error) {
+	results, err := f.client.Fetch(
+		ctx,
+		jackett.NewTVSearch().
+			WithCategories(model.AnimeCategories...).
+			WithQuery(query).
+			Build(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return f.processResults(ctx, results)
+}
+
 // Search does a generic Jackett search without category filters.
 // Used for magnet link lookups (movies, episodes, etc).
 func (f *Fetcher) Search(ctx context.Context, query string) ([]jackett.Result, error) {
@@ -175,6 +216,8 @@ func (f *Fetcher) FetchByType(
 		return f.FetchMovies(ctx, query)
 	case model.ContentTypeTV:
 		return f.FetchTV(ctx, query)
+	case model.ContentTypeAnime:
+		return f.FetchAnime(ctx, query)
 	default:
 		return nil, fmt.Errorf("unknown content type: %s", contentType)
 	}
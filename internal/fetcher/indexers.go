@@ -0,0 +1,85 @@
+package fetcher
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/unedtamps/orbit/internal/model"
+
+	jackett "github.com/webtor-io/go-jackett"
+)
+
+const (
+	// allIndexers is Jackett's name for searching every configured indexer.
+	allIndexers = "all"
+	// indexerTTL is how long the configured indexer list is reused.
+	indexerTTL = 10 * time.Minute
+)
+
+// Indexer is a tracker configured in Jackett.
+type Indexer struct {
+	ID    string `xml:"id,attr" json:"id"`
+	Title string `xml:"title" json:"title"`
+}
+
+type indexerList struct {
+	Indexers []Indexer `xml:"indexer"`
+}
+
+// Indexers lists the indexers configured in Jackett, cached for
+// indexerTTL.
+func (f *Fetcher) Indexers(ctx context.Context) ([]Indexer, error) {
+	f.mu.Lock()
+	cached, fetched := f.indexers, f.indexersFetched
+	f.mu.Unlock()
+	if cached != nil && time.Since(fetched) < indexerTTL {
+		return cached, nil
+	}
+
+	params := url.Values{}
+	params.Set("t", "indexers")
+	params.Set("configured", "true")
+	var list indexerList
+	if err := f.torznab(ctx, allIndexers, params, &list); err != nil {
+		return nil, err
+	}
+	sort.Slice(list.Indexers, func(i, j int) bool { return list.Indexers[i].Title < list.Indexers[j].Title })
+
+	f.mu.Lock()
+	f.indexers, f.indexersFetched = list.Indexers, time.Now()
+	f.mu.Unlock()
+	return list.Indexers, nil
+}
+
+// SearchOn is Search limited to one indexer.
+func (f *Fetcher) SearchOn(ctx context.Context, indexer, query string) ([]jackett.Result, error) {
+	results, err := f.client.Fetch(
+		ctx,
+		jackett.NewRawSearch().
+			WithTrackers(indexer).
+			WithQuery(query).
+			Build(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return f.processResults(ctx, results)
+}
+
+// FetchAnimeOn is FetchAnime limited to one indexer.
+func (f *Fetcher) FetchAnimeOn(ctx context.Context, indexer, query string) ([]jackett.Result, error) {
+	results, err := f.client.Fetch(
+		ctx,
+		jackett.NewTVSearch().
+			WithCategories(model.AnimeCategories...).
+			WithTrackers(indexer).
+			WithQuery(query).
+			Build(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return f.processResults(ctx, results)
+}
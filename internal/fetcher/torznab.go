@@ -0,0 +1,148 @@
+package fetcher
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	jackett "github.com/webtor-io/go-jackett"
+)
+
+// IDQuery is an ID-based lookup. Set IMDbID for a movie, or TVDBID (and
+// optionally IMDbID) with Season and Episode for an episode.
+type IDQuery struct {
+	IMDbID  string
+	TVDBID  int
+	Season  int
+	Episode int
+}
+
+// torznabFeed is an RSS feed of results, or, when XMLName is "error", a
+// Torznab error with its code and description.
+type torznabFeed struct {
+	XMLName     xml.Name
+	Code        string        `xml:"code,attr"`
+	Description string        `xml:"description,attr"`
+	Items       []torznabItem `xml:"channel>item"`
+}
+
+type torznabItem struct {
+	Title   string `xml:"title"`
+	Link    string `xml:"link"`
+	PubDate string `xml:"pubDate"`
+	Size    uint64 `xml:"size"`
+	Indexer string `xml:"jackettindexer"`
+	Attrs   []struct {
+		Name  string `xml:"name,attr"`
+		Value string `xml:"value,attr"`
+	} `xml:"attr"`
+}
+
+// SearchByID asks every configured indexer through Jackett's Torznab
+// endpoint, which, unlike the JSON API, accepts IMDb and TVDB IDs.
+// Indexers without ID support simply return nothing.
+func (f *Fetcher) SearchByID(ctx context.Context, q IDQuery) ([]jackett.Result, error) {
+	return f.SearchByIDOn(ctx, allIndexers, q)
+}
+
+// SearchByIDOn is SearchByID limited to one indexer.
+func (f *Fetcher) SearchByIDOn(ctx context.Context, indexer string, q IDQuery) ([]jackett.Result, error) {
+	params := url.Values{}
+	if q.TVDBID != 0 || q.Season != 0 {
+		params.Set("t", "tvsearch")
+		if q.TVDBID != 0 {
+			params.Set("tvdbid", strconv.Itoa(q.TVDBID))
+		}
+		if q.Season != 0 {
+			params.Set("season", strconv.Itoa(q.Season))
+		}
+		if q.Episode != 0 {
+			params.Set("ep", strconv.Itoa(q.Episode))
+		}
+	} else {
+		params.Set("t", "movie")
+	}
+	if q.IMDbID != "" {
+		params.Set("imdbid", q.IMDbID)
+	}
+
+	var feed torznabFeed
+	if err := f.torznab(ctx, indexer, params, &feed); err != nil {
+		return nil, err
+	}
+	if feed.XMLName.Local == "error" {
+		return nil, fmt.Errorf("torznab error %s: %s", feed.Code, feed.Description)
+	}
+
+	results := make([]jackett.Result, 0, len(feed.Items))
+	for _, item := range feed.Items {
+		results = append(results, item.result())
+	}
+	return f.processResults(ctx, results)
+}
+
+// torznab calls Jackett's Torznab endpoint for an indexer, or for
+// allIndexers, and decodes the XML reply into v.
+func (f *Fetcher) torznab(ctx context.Context, indexer string, params url.Values, v interface{}) error {
+	params.Set("apikey", f.apiKey)
+	endpoint := fmt.Sprintf("%s/api/v2.0/indexers/%s/results/torznab/api?%s",
+		strings.TrimSuffix(f.apiURL, "/"), url.PathEscape(indexer), params.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("torznab request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read torznab response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("torznab error (status %d)", resp.StatusCode)
+	}
+	if err := xml.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to parse torznab response: %w", err)
+	}
+	return nil
+}
+
+func (item torznabItem) result() jackett.Result {
+	r := jackett.Result{
+		Title:   item.Title,
+		Tracker: item.Indexer,
+		Link:    item.Link,
+		Size:    item.Size,
+	}
+	if t, err := time.Parse(time.RFC1123Z, item.PubDate); err == nil {
+		r.PublishDate = t
+	}
+	for _, a := range item.Attrs {
+		switch a.Name {
+		case "seeders":
+			n, _ := strconv.ParseUint(a.Value, 10, 32)
+			r.Seeders = uint(n)
+		case "peers":
+			n, _ := strconv.ParseUint(a.Value, 10, 32)
+			r.Peers = uint(n)
+		case "infohash":
+			r.InfoHash = a.Value
+		case "magneturl":
+			r.MagnetURI = a.Value
+		case "size":
+			if r.Size == 0 {
+				r.Size, _ = strconv.ParseUint(a.Value, 10, 64)
+			}
+		}
+	}
+	return r
+}
@@ -0,0 +1,388 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/unedtamps/orbit/internal/openapi"
+	"github.com/unedtamps/orbit/internal/tmdb"
+)
+
+// APIPrefix is where the versioned API is served.
+const APIPrefix = "/api/v1"
+
+// legacyDeprecated is when the unversioned /api paths were deprecated in
+// favour of APIPrefix.
+var legacyDeprecated = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+
+// errorResponse is the body of every JSON error.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+type resolveLinkResponse struct {
+	URL string `json:"url"`
+}
+
+// Request models. The document lists their parameters and handlers read
+// them with decodeRequest, so both come from the same tags.
+type (
+	idRequest struct {
+		ID int `path:"id" doc:"TMDB ID"`
+	}
+	pageRequest struct {
+		Page int `query:"page" doc:"Page, from 1"`
+	}
+	regionRequest struct {
+		Region string `query:"region" doc:"Watch region (ISO 3166-1), defaulting to the saved or instance region"`
+	}
+	formatRequest struct {
+		Format string `query:"format" enum:"json,html" doc:"Response format, overriding the Accept header"`
+	}
+	idPageRequest struct {
+		idRequest
+		pageRequest
+	}
+	idRegionRequest struct {
+		idRequest
+		regionRequest
+	}
+	searchRequest struct {
+		Query string `query:"q" required:"true" doc:"Search query"`
+		pageRequest
+	}
+	seasonRequest struct {
+		idRequest
+		Season int `path:"season" doc:"Season number, 0 for specials"`
+	}
+	personRequest struct {
+		idRequest
+		Sort string `query:"sort" enum:"year,popularity" doc:"Filmography order"`
+	}
+	findRequest struct {
+		ID     string `path:"id" doc:"External ID"`
+		Source string `query:"source" enum:"imdb_id,tvdb_id,wikidata_id,facebook_id,instagram_id,twitter_id,tiktok_id,youtube_id" doc:"Where the ID is from, imdb_id by default"`
+	}
+	trendingRequest struct {
+		Window string `query:"window" enum:"day,week" doc:"Trending window, week by default"`
+	}
+	genresRequest struct {
+		Language string `query:"language" doc:"Name language, en-US by default"`
+	}
+	genreTitlesRequest struct {
+		idRequest
+		Type string `query:"type" enum:"movie,tv" doc:"Media type, movie by default"`
+		pageRequest
+	}
+	regionPageRequest struct {
+		regionRequest
+		pageRequest
+	}
+	calendarRequest struct {
+		Days int `query:"days" doc:"Days ahead, 14 by default"`
+		regionRequest
+	}
+	releaseRequest struct {
+		MinSeeders     uint     `query:"min_seeders" doc:"Minimum seeders"`
+		MinSize        string   `query:"min_size" doc:"Minimum size, e.g. 700MB"`
+		MaxSize        string   `query:"max_size" doc:"Maximum size, e.g. 4GB"`
+		Tracker        []string `query:"tracker" doc:"Trackers, repeated or comma-separated"`
+		PublishedAfter string   `query:"published_after" doc:"Published on or after (YYYY-MM-DD)"`
+		Resolution     []string `query:"resolution" doc:"Resolutions, repeated or comma-separated (2160p, 1080p, 720p, 576p, 480p)"`
+		Codec          []string `query:"codec" doc:"Codecs, repeated or comma-separated (x264, x265, av1, vp9, xvid)"`
+		Sort           string   `query:"sort" enum:"seeders,peers,size,date,title" doc:"Sort key"`
+		Order          string   `query:"order" enum:"asc,desc" doc:"Sort direction"`
+		pageRequest
+		PerPage int `query:"per_page" doc:"Results per page, at most 100"`
+	}
+	torrentSearchRequest struct {
+		Query string `path:"query" doc:"Search query"`
+		releaseRequest
+	}
+	availabilityRequest struct {
+		seasonRequest
+		Episodes []int `query:"episodes" doc:"Episode numbers to check, repeated or comma-separated; all by default"`
+	}
+	movieMagnetRequest struct {
+		idRequest
+		Title    string `query:"title" required:"true" doc:"Movie title"`
+		Original string `query:"original" doc:"Original title"`
+		Year     string `query:"year" doc:"Release year"`
+		releaseRequest
+		formatRequest
+	}
+	episodeMagnetRequest struct {
+		idRequest
+		Season   int    `path:"season" doc:"Season number"`
+		Episode  int    `path:"episode" doc:"Episode number"`
+		Name     string `query:"name" required:"true" doc:"Show name"`
+		Original string `query:"original" doc:"Original show name"`
+		Title    string `query:"title" doc:"Episode title"`
+		AirDate  string `query:"air_date" doc:"Air date (YYYY-MM-DD), for daily shows"`
+		Type     string `query:"type" enum:"movies,tv,anime" doc:"Overrides the anime detection"`
+		releaseRequest
+		formatRequest
+	}
+	personMagnetRequest struct {
+		idRequest
+		Limit int    `query:"limit" doc:"How many movies to search, 10 by default"`
+		Sort  string `query:"sort" enum:"year,popularity" doc:"Which movies come first"`
+		formatRequest
+	}
+	collectionMagnetRequest struct {
+		idRequest
+		formatRequest
+	}
+	resolveLinkRequest struct {
+		URL string `query:"url" required:"true" doc:"A /dl/ proxy URL"`
+	}
+	downloadRequest struct {
+		Tracker string `path:"tracker" doc:"Jackett indexer ID"`
+		Path    string `query:"path" required:"true" doc:"Jackett download path"`
+		File    string `query:"file" doc:"File name"`
+		Sig     string `query:"sig" doc:"Signature the server added to the link"`
+	}
+	pageSearchRequest struct {
+		Query string `query:"q" doc:"Search query, or an IMDb, TMDB, Letterboxd or Trakt link"`
+		formatRequest
+	}
+	pageIDRequest struct {
+		idRequest
+		formatRequest
+	}
+	pageSeasonRequest struct {
+		seasonRequest
+		formatRequest
+	}
+	pagePersonRequest struct {
+		personRequest
+		formatRequest
+	}
+	pageGenreRequest struct {
+		genreTitlesRequest
+		formatRequest
+	}
+	pageCalendarRequest struct {
+		calendarRequest
+		formatRequest
+	}
+	externalIDRequest struct {
+		ID string `path:"id" doc:"IMDb (tt...) or TVDB ID"`
+	}
+	lookupRequest struct {
+		URL string `query:"url" required:"true" doc:"IMDb, TMDB, Letterboxd or Trakt link"`
+	}
+	feedRequest struct {
+		TV    []int `query:"tv" doc:"TMDB TV IDs, repeated or comma-separated"`
+		Movie []int `query:"movie" doc:"TMDB movie IDs, repeated or comma-separated"`
+		regionRequest
+	}
+)
+
+// decodeRequest reads the request model req points to from r's path and
+// query parameters.
+func decodeRequest(r *http.Request, req interface{}) error {
+	return openapi.Decode(r, req, chi.URLParam)
+}
+
+// APIRoutes are the JSON API routes, served under APIPrefix. Those with a
+// Legacy path are also served there as deprecated aliases.
+func APIRoutes(h *Handler, t *TMDBHandler, m *MagnetHandler) []openapi.Route {
+	return []openapi.Route{
+		{Method: "GET", Path: "/search", Name: "search", Summary: "TMDB multi-search (movies and TV)", Tag: "tmdb",
+			Params: searchRequest{}, Response: searchResponse{}, Legacy: "/api/search", Handler: t.Search},
+		{Method: "GET", Path: "/movie/{id}", Name: "getMovie", Summary: "Movie details with credits, reviews, recommendations, availability and release schedule", Tag: "tmdb",
+			Params: idRegionRequest{}, Response: movieResponse{}, Legacy: "/api/movie/{id}", Handler: t.GetMovie},
+		{Method: "GET", Path: "/movie/{id}/reviews", Name: "getMovieReviews", Summary: "Movie reviews", Tag: "tmdb",
+			Params: idPageRequest{}, Response: tmdb.ReviewResponse{}, Legacy: "/api/movie/{id}/reviews", Handler: t.GetMovieReviews},
+		{Method: "GET", Path: "/movie/{id}/recommendations", Name: "getMovieRecommendations", Summary: "Recommended titles for a movie", Tag: "tmdb",
+			Params: idPageRequest{}, Response: tmdb.MultiSearchResponse{}, Legacy: "/api/movie/{id}/recommendations", Handler: t.GetMovieRecommendations},
+		{Method: "GET", Path: "/movie/{id}/similar", Name: "getSimilarMovies", Summary: "Similar movies", Tag: "tmdb",
+			Params: idPageRequest{}, Response: tmdb.MultiSearchResponse{}, Legacy: "/api/movie/{id}/similar", Handler: t.GetSimilarMovies},
+		{Method: "GET", Path: "/movie/{id}/watch-providers", Name: "getMovieWatchProviders", Summary: "Streaming, rent and buy offers for a movie", Tag: "tmdb",
+			Params: idRegionRequest{}, Response: watchProvidersResponse{}, Legacy: "/api/movie/{id}/watch-providers", Handler: t.GetMovieWatchProviders},
+		{Method: "GET", Path: "/movie/{id}/videos", Name: "getMovieVideos", Summary: "Movie trailers and videos, best trailer first", Tag: "tmdb",
+			Params: idRequest{}, Response: tmdb.VideoResponse{}, Legacy: "/api/movie/{id}/videos", Handler: t.GetMovieVideos},
+		{Method: "GET", Path: "/movie/{id}/release-dates", Name: "getMovieReleaseDates", Summary: "Release dates by country with the region's release schedule", Tag: "tmdb",
+			Params: idRegionRequest{}, Response: releaseDatesResponse{}, Legacy: "/api/movie/{id}/release-dates", Handler: t.GetMovieReleaseDates},
+		{Method: "GET", Path: "/movie/{id}/alternative-titles", Name: "getMovieAlternativeTitles", Summary: "Movie alternative titles, translations and scene titles", Tag: "tmdb",
+			Params: idRequest{}, Response: tmdb.AltTitlesResponse{}, Legacy: "/api/movie/{id}/alternative-titles", Handler: t.GetMovieAlternativeTitles},
+		{Method: "GET", Path: "/tv/{id}", Name: "getTV", Summary: "TV show details with credits, recommendations and availability", Tag: "tmdb",
+			Params: idRegionRequest{}, Response: tvResponse{}, Legacy: "/api/tv/{id}", Handler: t.GetTV},
+		{Method: "GET", Path: "/tv/{id}/season/{season}", Name: "getSeason", Summary: "Season details with episodes", Tag: "tmdb",
+			Params: seasonRequest{}, Response: seasonResponse{}, Legacy: "/api/tv/{id}/season/{season}", Handler: t.GetSeason},
+		{Method: "GET", Path: "/tv/{id}/reviews", Name: "getTVReviews", Summary: "TV show reviews", Tag: "tmdb",
+			Params: idPageRequest{}, Response: tmdb.ReviewResponse{}, Legacy: "/api/tv/{id}/reviews", Handler: t.GetTVReviews},
+		{Method: "GET", Path: "/tv/{id}/recommendations", Name: "getTVRecommendations", Summary: "Recommended titles for a TV show", Tag: "tmdb",
+			Params: idPageRequest{}, Response: tmdb.MultiSearchResponse{}, Legacy: "/api/tv/{id}/recommendations", Handler: t.GetTVRecommendations},
+		{Method: "GET", Path: "/tv/{id}/similar", Name: "getSimilarTV", Summary: "Similar TV shows", Tag: "tmdb",
+			Params: idPageRequest{}, Response: tmdb.MultiSearchResponse{}, Legacy: "/api/tv/{id}/similar", Handler: t.GetSimilarTV},
+		{Method: "GET", Path: "/tv/{id}/watch-providers", Name: "getTVWatchProviders", Summary: "Streaming, rent and buy offers for a TV show", Tag: "tmdb",
+			Params: idRegionRequest{}, Response: watchProvidersResponse{}, Legacy: "/api/tv/{id}/watch-providers", Handler: t.GetTVWatchProviders},
+		{Method: "GET", Path: "/tv/{id}/videos", Name: "getTVVideos", Summary: "TV show trailers and videos, best trailer first", Tag: "tmdb",
+			Params: idRequest{}, Response: tmdb.VideoResponse{}, Legacy: "/api/tv/{id}/videos", Handler: t.GetTVVideos},
+		{Method: "GET", Path: "/tv/{id}/external-ids", Name: "getTVExternalIDs", Summary: "IMDb, TVDB and social IDs of a TV show", Tag: "tmdb",
+			Params: idRequest{}, Response: tmdb.ExternalIDs{}, Legacy: "/api/tv/{id}/external-ids", Handler: t.GetTVExternalIDs},
+		{Method: "GET", Path: "/tv/{id}/alternative-titles", Name: "getTVAlternativeTitles", Summary: "TV show alternative names, translations and scene names", Tag: "tmdb",
+			Params: idRequest{}, Response: tmdb.AltTitlesResponse{}, Legacy: "/api/tv/{id}/alternative-titles", Handler: t.GetTVAlternativeTitles},
+		{Method: "GET", Path: "/person/{id}", Name: "getPerson", Summary: "Person details with combined credits and sorted filmography", Tag: "tmdb",
+			Params: personRequest{}, Response: personResponse{}, Legacy: "/api/person/{id}", Handler: t.GetPerson},
+		{Method: "GET", Path: "/collection/{id}", Name: "getCollection", Summary: "Collection details with parts in release order", Tag: "tmdb",
+			Params: idRequest{}, Response: tmdb.Collection{}, Legacy: "/api/collection/{id}", Handler: t.GetCollection},
+		{Method: "GET", Path: "/find/{id}", Name: "find", Summary: "TMDB objects matching an external ID", Tag: "tmdb",
+			Params: findRequest{}, Response: tmdb.FindResponse{}, Legacy: "/api/find/{id}", Handler: t.Find},
+		{Method: "GET", Path: "/watch/providers", Name: "getWatchProviders", Summary: "Streaming providers available in a region", Tag: "tmdb",
+			Params: regionRequest{}, Response: providerListResponse{}, Legacy: "/api/watch/providers", Handler: t.GetWatchProviderList},
+		{Method: "GET", Path: "/watch/regions", Name: "getWatchRegions", Summary: "Regions with availability data", Tag: "tmdb",
+			Response: regionsResponse{}, Legacy: "/api/watch/regions", Handler: t.GetWatchRegions},
+		{Method: "GET", Path: "/genres", Name: "getGenres", Summary: "Movie and TV genres", Tag: "tmdb",
+			Params: genresRequest{}, Response: genresResponse{}, Legacy: "/api/genres", Handler: t.GetGenres},
+		{Method: "GET", Path: "/genre/{id}", Name: "getGenreTitles", Summary: "Popular titles of a genre", Tag: "tmdb",
+			Params: genreTitlesRequest{}, Response: genreTitlesResponse{}, Legacy: "/api/genre/{id}", Handler: t.GetGenreTitles},
+		{Method: "GET", Path: "/trending/movies", Name: "getTrendingMovies", Summary: "Trending movies", Tag: "tmdb",
+			Params: trendingRequest{}, Response: tmdb.MultiSearchResponse{}, Legacy: "/api/trending/movies", Handler: t.GetTrendingMovies},
+		{Method: "GET", Path: "/trending/tv", Name: "getTrendingTV", Summary: "Trending TV shows", Tag: "tmdb",
+			Params: trendingRequest{}, Response: tmdb.MultiSearchResponse{}, Legacy: "/api/trending/tv", Handler: t.GetTrendingTV},
+		{Method: "GET", Path: "/upcoming/movies", Name: "getUpcomingMovies", Summary: "Upcoming movies in a region", Tag: "tmdb",
+			Params: regionPageRequest{}, Response: tmdb.MultiSearchResponse{}, Legacy: "/api/upcoming/movies", Handler: t.GetUpcomingMovies},
+		{Method: "GET", Path: "/now-playing/movies", Name: "getNowPlayingMovies", Summary: "Movies now in theatres in a region", Tag: "tmdb",
+			Params: regionPageRequest{}, Response: tmdb.MultiSearchResponse{}, Legacy: "/api/now-playing/movies", Handler: t.GetNowPlayingMovies},
+		{Method: "GET", Path: "/airing-today/tv", Name: "getAiringTodayTV", Summary: "TV shows airing today", Tag: "tmdb",
+			Params: pageRequest{}, Response: tmdb.MultiSearchResponse{}, Legacy: "/api/airing-today/tv", Handler: t.GetAiringTodayTV},
+		{Method: "GET", Path: "/on-the-air/tv", Name: "getOnTheAirTV", Summary: "TV shows airing in the next seven days", Tag: "tmdb",
+			Params: pageRequest{}, Response: tmdb.MultiSearchResponse{}, Legacy: "/api/on-the-air/tv", Handler: t.GetOnTheAirTV},
+		{Method: "GET", Path: "/calendar", Name: "getCalendar", Summary: "Release calendar grouped by date", Tag: "tmdb",
+			Params: calendarRequest{}, Response: calendarResponse{}, Legacy: "/api/calendar", Handler: t.GetCalendar},
+
+		{Method: "GET", Path: "/movies/search/{query}", Name: "searchMovieTorrents", Summary: "Torrent search in the movie categories", Tag: "torrents",
+			Params: torrentSearchRequest{}, Response: ReleasePage{}, Legacy: "/api/movies/search/{query}", Handler: h.GetMovies},
+		{Method: "GET", Path: "/tv/search/{query}", Name: "searchTVTorrents", Summary: "Torrent search in the TV categories", Tag: "torrents",
+			Params: torrentSearchRequest{}, Response: ReleasePage{}, Legacy: "/api/tv/search/{query}", Handler: h.GetTV},
+		{Method: "GET", Path: "/anime/search/{query}", Name: "searchAnimeTorrents", Summary: "Torrent search in the anime categories", Tag: "torrents",
+			Params: torrentSearchRequest{}, Response: ReleasePage{}, Legacy: "/api/anime/search/{query}", Handler: h.GetAnime},
+		{Method: "GET", Path: "/tv/{id}/season/{season}/availability", Name: "getSeasonAvailability", Summary: "Best release quality and seeders per aired episode", Tag: "torrents",
+			Params: availabilityRequest{}, Response: SeasonAvailability{}, Legacy: "/api/tv/{id}/season/{season}/availability", Handler: m.GetSeasonAvailability},
+		{Method: "GET", Path: "/magnet/movie/{id}", Name: "getMovieMagnets", Summary: "Releases of a movie, filtered, sorted and paged", Tag: "torrents",
+			Params: movieMagnetRequest{}, Response: MagnetPage{}, Produces: []string{openapi.JSON, openapi.HTML}, Handler: m.GetMovieMagnets},
+		{Method: "GET", Path: "/magnet/movie/{id}/stream", Name: "streamMovieMagnets", Summary: "Releases of a movie as server-sent events (results, progress, indexer-error, done); results carry MagnetRelease JSON arrays", Tag: "torrents",
+			Params: movieMagnetRequest{}, Produces: []string{openapi.EventStream}, Handler: m.GetMovieMagnetStream},
+		{Method: "GET", Path: "/magnet/episode/{id}/s{season}/e{episode}", Name: "getEpisodeMagnets", Summary: "Releases of an episode, filtered, sorted and paged", Tag: "torrents",
+			Params: episodeMagnetRequest{}, Response: MagnetPage{}, Produces: []string{openapi.JSON, openapi.HTML}, Handler: m.GetEpisodeMagnets},
+		{Method: "GET", Path: "/magnet/episode/{id}/s{season}/e{episode}/stream", Name: "streamEpisodeMagnets", Summary: "Releases of an episode as server-sent events; results carry MagnetRelease JSON arrays", Tag: "torrents",
+			Params: episodeMagnetRequest{}, Produces: []string{openapi.EventStream}, Handler: m.GetEpisodeMagnetStream},
+		{Method: "GET", Path: "/magnet/person/{id}", Name: "getPersonMagnets", Summary: "Best release of each of a person's top released movies", Tag: "torrents",
+			Params: personMagnetRequest{}, Response: BatchResponse{}, Produces: []string{openapi.JSON, openapi.HTML}, Handler: m.GetPersonMagnets},
+		{Method: "GET", Path: "/magnet/collection/{id}", Name: "getCollectionMagnets", Summary: "Best release of every film in a collection, plus collection packs", Tag: "torrents",
+			Params: collectionMagnetRequest{}, Response: BatchResponse{}, Produces: []string{openapi.JSON, openapi.HTML}, Handler: m.GetCollectionMagnets},
+		{Method: "GET", Path: "/resolve-link", Name: "resolveLink", Summary: "Where a /dl/ proxy link redirects to, such as a magnet", Tag: "torrents",
+			Params: resolveLinkRequest{}, Response: resolveLinkResponse{}, Legacy: "/api/resolve-link", Handler: h.ResolveLink},
+	}
+}
+
+// SiteRoutes are the browser-facing routes: pages, HTML magnet panels,
+// calendar feeds and redirects. Pages and magnet panels also answer in
+// JSON when asked.
+func SiteRoutes(h *Handler, m *MagnetHandler, ical *ICalHandler, lookup *LookupHandler) []openapi.Route {
+	page := []string{openapi.HTML, openapi.JSON}
+	return []openapi.Route{
+		{Method: "GET", Path: "/", Name: "indexPage", Summary: "Home page", Tag: "pages", Produces: []string{openapi.HTML}, Handler: h.Index},
+		{Method: "GET", Path: "/search", Name: "searchPage", Summary: "Search page; links jump to their title", Tag: "pages", Params: pageSearchRequest{}, Produces: page, Handler: h.SearchPage},
+		{Method: "GET", Path: "/movie/{id}", Name: "moviePage", Summary: "Movie page", Tag: "pages", Params: pageIDRequest{}, Produces: page, Handler: h.MovieDetailPage},
+		{Method: "GET", Path: "/tv/{id}", Name: "tvPage", Summary: "TV show page", Tag: "pages", Params: pageIDRequest{}, Produces: page, Handler: h.TVDetailPage},
+		{Method: "GET", Path: "/tv/{id}/season/{season}", Name: "seasonPage", Summary: "Season page", Tag: "pages", Params: pageSeasonRequest{}, Produces: page, Handler: h.SeasonPage},
+		{Method: "GET", Path: "/person/{id}", Name: "personPage", Summary: "Person page", Tag: "pages", Params: pagePersonRequest{}, Produces: page, Handler: h.PersonPage},
+		{Method: "GET", Path: "/collection/{id}", Name: "collectionPage", Summary: "Collection page", Tag: "pages", Params: pageIDRequest{}, Produces: page, Handler: h.CollectionPage},
+		{Method: "GET", Path: "/genre/{id}", Name: "genrePage", Summary: "Genre page", Tag: "pages", Params: pageGenreRequest{}, Produces: page, Handler: h.GenrePage},
+		{Method: "GET", Path: "/calendar", Name: "calendarPage", Summary: "Release calendar page", Tag: "pages", Params: pageCalendarRequest{}, Produces: page, Handler: h.CalendarPage},
+		{Method: "GET", Path: "/settings", Name: "settingsPage", Summary: "Settings page", Tag: "pages", Params: formatRequest{}, Produces: page, Handler: h.SettingsPage},
+		{Method: "POST", Path: "/settings", Name: "saveSettings", Summary: "Save watch region, providers and rating limits in cookies", Tag: "pages", Status: http.StatusSeeOther, Handler: h.SaveSettings},
+
+		{Method: "GET", Path: "/magnet/movie/{id}", Name: "movieMagnetPanel", Summary: "Movie magnet panel; ?stream=1 returns the streaming shell", Tag: "magnet panels",
+			Params: movieMagnetRequest{}, Response: MagnetPage{}, Produces: page, Handler: m.GetMovieMagnets},
+		{Method: "GET", Path: "/magnet/movie/{id}/stream", Name: "movieMagnetPanelStream", Summary: "Movie magnet panel events", Tag: "magnet panels",
+			Params: movieMagnetRequest{}, Produces: []string{openapi.EventStream}, Handler: m.GetMovieMagnetStream},
+		{Method: "GET", Path: "/magnet/episode/{id}/s{season}/e{episode}", Name: "episodeMagnetPanel", Summary: "Episode magnet panel; ?stream=1 returns the streaming shell", Tag: "magnet panels",
+			Params: episodeMagnetRequest{}, Response: MagnetPage{}, Produces: page, Handler: m.GetEpisodeMagnets},
+		{Method: "GET", Path: "/magnet/episode/{id}/s{season}/e{episode}/stream", Name: "episodeMagnetPanelStream", Summary: "Episode magnet panel events", Tag: "magnet panels",
+			Params: episodeMagnetRequest{}, Produces: []string{openapi.EventStream}, Handler: m.GetEpisodeMagnetStream},
+		{Method: "GET", Path: "/magnet/person/{id}", Name: "personMagnetPanel", Summary: "Person magnet panel", Tag: "magnet panels",
+			Params: personMagnetRequest{}, Response: BatchResponse{}, Produces: page, Handler: m.GetPersonMagnets},
+		{Method: "GET", Path: "/magnet/collection/{id}", Name: "collectionMagnetPanel", Summary: "Collection magnet panel", Tag: "magnet panels",
+			Params: collectionMagnetRequest{}, Response: BatchResponse{}, Produces: page, Handler: m.GetCollectionMagnets},
+		{Method: "GET", Path: "/dl/{tracker}", Name: "download", Summary: "Download proxy hiding the Jackett API key; only serves links this server signed", Tag: "magnet panels",
+			Params: downloadRequest{}, Produces: []string{openapi.Torrent}, Handler: h.DownloadProxy},
+
+		{Method: "GET", Path: "/calendar.ics", Name: "combinedFeed", Summary: "Combined calendar feed for up to 20 titles", Tag: "feeds",
+			Params: feedRequest{}, Produces: []string{openapi.Calendar}, Handler: ical.CombinedFeed},
+		{Method: "GET", Path: "/calendar/tv/{id}.ics", Name: "tvFeed", Summary: "Every dated episode of a TV show", Tag: "feeds",
+			Params: idRequest{}, Produces: []string{openapi.Calendar}, Handler: ical.TVFeed},
+		{Method: "GET", Path: "/calendar/movie/{id}.ics", Name: "movieFeed", Summary: "Theatrical, digital and physical release dates of a movie", Tag: "feeds",
+			Params: idRegionRequest{}, Produces: []string{openapi.Calendar}, Handler: ical.MovieFeed},
+
+		{Method: "GET", Path: "/imdb/{id}", Name: "imdbRedirect", Summary: "Redirect from an IMDb ID to its page", Tag: "lookup",
+			Params: externalIDRequest{}, Status: http.StatusFound, Handler: lookup.IMDb},
+		{Method: "GET", Path: "/tvdb/{id}", Name: "tvdbRedirect", Summary: "Redirect from a TVDB ID to its page", Tag: "lookup",
+			Params: externalIDRequest{}, Status: http.StatusFound, Handler: lookup.TVDB},
+		{Method: "GET", Path: "/lookup", Name: "lookupRedirect", Summary: "Redirect from a pasted link to its page", Tag: "lookup",
+			Params: lookupRequest{}, Status: http.StatusFound, Handler: lookup.Lookup},
+	}
+}
+
+// OpenAPI documents the versioned API, its legacy aliases and the site.
+func OpenAPI(api, site []openapi.Route) *openapi.Document {
+	b := openapi.NewBuilder(openapi.Info{
+		Title:       "OrbitSearch API",
+		Version:     "1.0",
+		Description: "Content discovery API: TMDB metadata, torrent search and magnet lookups. The unversioned /api paths are deprecated aliases of " + APIPrefix + ".",
+		License:     &openapi.License{Name: "Apache 2.0", URL: "http://www.apache.org/licenses/LICENSE-2.0.html"},
+	})
+	b.Tag("tmdb", "Movie, TV and people metadata")
+	b.Tag("torrents", "Torrent search and magnet lookups")
+	b.Tag("pages", "Browser pages, also served as JSON")
+	b.Tag("magnet panels", "The HTML magnet panels the pages load, and the download proxy")
+	b.Tag("feeds", "iCalendar feeds")
+	b.Tag("lookup", "Redirects from external IDs and links")
+	b.Substitute(MagnetHit{}, MagnetRelease{})
+	b.Add(openapi.Group{Prefix: APIPrefix, Routes: api})
+	b.Add(openapi.Group{Routes: site})
+	b.Error(errorResponse{})
+	return b.Document()
+}
+
+// Mount serves routes under prefix on r.
+func Mount(r interface {
+	Method(method, pattern string, h http.Handler)
+}, prefix string, routes []openapi.Route) {
+	for _, rt := range routes {
+		r.Method(rt.Method, prefix+rt.Path, rt.Handler)
+	}
+}
+
+// MountLegacy serves the routes' legacy paths as deprecated aliases.
+func MountLegacy(r interface {
+	Method(method, pattern string, h http.Handler)
+}, routes []openapi.Route) {
+	for _, rt := range routes {
+		if rt.Legacy != "" {
+			r.Method(rt.Method, rt.Legacy, deprecated(rt.Handler))
+		}
+	}
+}
+
+// deprecated marks responses from an unversioned alias with the
+// Deprecation header (RFC 9745) and a Link to the versioned path.
+func deprecated(next http.HandlerFunc) http.HandlerFunc {
+	header := fmt.Sprintf("@%d", legacyDeprecated.Unix())
+	return func(w http.ResponseWriter, r *http.Request) {
+		successor := APIPrefix + strings.TrimPrefix(r.URL.Path, "/api")
+		if r.URL.RawQuery != "" {
+			successor += "?" + r.URL.RawQuery
+		}
+		w.Header().Set("Deprecation", header)
+		w.Header().Add("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", successor))
+		next(w, r)
+	}
+}
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+
+	"github.com/unedtamps/orbit/internal/openapi"
+)
+
+// APIDocsHandler serves the OpenAPI document and a Swagger UI for it.
+type APIDocsHandler struct {
+	doc  *openapi.Document
+	tmpl *template.Template
+}
+
+func NewAPIDocsHandler(doc *openapi.Document, tmpl *template.Template) *APIDocsHandler {
+	return &APIDocsHandler{doc: doc, tmpl: tmpl}
+}
+
+// Spec writes the OpenAPI document.
+func (a *APIDocsHandler) Spec(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, a.doc)
+}
+
+// UI renders Swagger UI pointed at Spec.
+func (a *APIDocsHandler) UI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := a.tmpl.ExecuteTemplate(w, "apidocs.html", map[string]interface{}{
+		"SpecURL": "/apidocs/openapi.json",
+		"Title":   a.doc.Info.Title,
+	}); err != nil {
+		log.Printf("Template error (apidocs.html): %v", err)
+	}
+}
@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/unedtamps/orbit/internal/model"
+	"github.com/unedtamps/orbit/internal/tmdb"
+)
+
+const (
+	// availabilityTTL is how long an episode's availability is reused.
+	availabilityTTL = 30 * time.Minute
+	// availabilityCacheSize caps how many episodes' hits are kept.
+	availabilityCacheSize = 1024
+	// availabilityConcurrency is how many episodes are checked at once.
+	availabilityConcurrency = 3
+	// availabilityBudget is how many searches one episode check may run,
+	// far fewer than a full magnet lookup.
+	availabilityBudget = 2
+)
+
+// Availability statuses.
+const (
+	AvailabilityFound   = "available"
+	AvailabilityNone    = "none"
+	AvailabilityUnaired = "unaired"
+	AvailabilityError   = "error"
+)
+
+// EpisodeAvailability is the best release found for one episode.
+type EpisodeAvailability struct {
+	EpisodeNumber int    `json:"episode_number"`
+	Status        string `json:"status"`
+	Quality       string `json:"quality,omitempty"`
+	Seeders       uint   `json:"seeders"`
+	Release       string `json:"release,omitempty"`
+	Match         string `json:"match,omitempty"`
+}
+
+type SeasonAvailability struct {
+	ID           int                   `json:"id"`
+	SeasonNumber int                   `json:"season_number"`
+	Episodes     []EpisodeAvailability `json:"episodes"`
+}
+
+func availabilityKey(id, season, episode int) string {
+	return fmt.Sprintf("%d/%d/%d", id, season, episode)
+}
+
+// qualityRank orders release resolutions.
+var qualityRank = map[string]int{"2160p": 4, "1080p": 3, "720p": 2, "576p": 1, "480p": 1}
+
+// bestRelease picks the highest-quality seeded hit that passes rf,
+// breaking ties by seeders. Episode plans only return hits covering the
+// episode.
+func bestRelease(hits []MagnetHit, rf *ratingFilter) (best MagnetHit, ok bool) {
+	bestRank := -1
+	for _, hit := range hits {
+		if hit.Seeders == 0 || !rf.allowsRelease(hit.Title) {
+			continue
+		}
+		rank := qualityRank[model.ParseResolution(hit.Title)]
+		if rank > bestRank || (rank == bestRank && hit.Seeders > best.Seeders) {
+			best, bestRank, ok = hit, rank, true
+		}
+	}
+	return best, ok
+}
+
+// checkEpisode runs a short search plan for one episode, through the
+// cache. The cache holds every hit, so each caller's rating filter picks
+// from the same releases. Failed checks are not cached.
+func (h *MagnetHandler) checkEpisode(ctx context.Context, id int, t episodeTarget, rf *ratingFilter) EpisodeAvailability {
+	key := availabilityKey(id, t.Season, t.Episode)
+	hits, ok := h.availability.Get(key)
+	if !ok {
+		p := episodePlan(t)
+		p.budget = availabilityBudget
+		var err error
+		if hits, err = h.run(ctx, p); err != nil {
+			log.Printf("Availability search error (%d s%02de%02d): %v", id, t.Season, t.Episode, err)
+			return EpisodeAvailability{EpisodeNumber: t.Episode, Status: AvailabilityError}
+		}
+		h.availability.Put(key, hits)
+	}
+
+	result := EpisodeAvailability{EpisodeNumber: t.Episode, Status: AvailabilityNone}
+	if best, ok := bestRelease(hits, rf); ok {
+		result.Status = AvailabilityFound
+		result.Quality = model.ParseResolution(best.Title)
+		result.Seeders = best.Seeders
+		result.Release = best.Title
+		result.Match = best.Match
+	}
+	return result
+}
+
+// episodeSet turns the requested episode numbers into a set, or returns
+// nil for all episodes.
+func episodeSet(list []int) map[int]bool {
+	if len(list) == 0 {
+		return nil
+	}
+	wanted := make(map[int]bool, len(list))
+	for _, n := range list {
+		wanted[n] = true
+	}
+	return wanted
+}
+
+// GetSeasonAvailability reports, per episode of a season, the best
+// release quality found and its seeders. ?episodes=1,2,3 limits the check
+// to some episodes so pages can ask in small batches and fill in badges
+// as answers come back. Episodes not yet aired are not searched.
+func (h *MagnetHandler) GetSeasonAvailability(w http.ResponseWriter, r *http.Request) {
+	var req availabilityRequest
+	if err := decodeRequest(r, &req); err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	id, season := req.ID, req.Season
+
+	tv, err := h.tmdb.GetTVTitles(id)
+	if err != nil {
+		log.Printf("Availability show lookup error: %v", err)
+		writeJSONError(w, cleanTMDBError(err), http.StatusBadGateway)
+		return
+	}
+	details, err := h.tmdb.GetSeasonDetails(id, season)
+	if err != nil {
+		log.Printf("Availability season lookup error: %v", err)
+		writeJSONError(w, cleanTMDBError(err), http.StatusBadGateway)
+		return
+	}
+
+	wanted := episodeSet(req.Episodes)
+	var episodes []tmdb.Episode
+	for _, e := range details.Episodes {
+		if wanted == nil || wanted[e.EpisodeNumber] {
+			episodes = append(episodes, e)
+		}
+	}
+
+	rf := h.ratings(r)
+	today := time.Now().Format("2006-01-02")
+	resp := SeasonAvailability{ID: id, SeasonNumber: season, Episodes: make([]EpisodeAvailability, len(episodes))}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, availabilityConcurrency)
+	for i, e := range episodes {
+		if e.AirDate == "" || e.AirDate > today {
+			resp.Episodes[i] = EpisodeAvailability{EpisodeNumber: e.EpisodeNumber, Status: AvailabilityUnaired}
+			continue
+		}
+		wg.Add(1)
+		go func(i int, e tmdb.Episode) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			t := episodeTarget{Season: season, Episode: e.EpisodeNumber, EpisodeTitle: e.Name, AirDate: e.AirDate}
+			t.fromShow(tv)
+			resp.Episodes[i] = h.checkEpisode(r.Context(), id, t, rf)
+		}(i, e)
+	}
+	wg.Wait()
+
+	writeJSON(w, resp)
+}
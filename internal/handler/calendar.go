@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/unedtamps/orbit/internal/tmdb"
+)
+
+const (
+	// calendarMoviePages is how many pages of upcoming movies are scanned.
+	calendarMoviePages = 2
+	// calendarMaxShows caps the airing shows whose next episode is looked up.
+	calendarMaxShows = 20
+	// calendarConcurrency bounds parallel TV detail requests.
+	calendarConcurrency = 4
+
+	defaultCalendarDays = 14
+	maxCalendarDays     = 60
+)
+
+// calendarDays bounds the ?days= window, defaulting to two weeks.
+func calendarDays(n int) int {
+	days := defaultCalendarDays
+	if n > 0 {
+		days = n
+	}
+	if days > maxCalendarDays {
+		days = maxCalendarDays
+	}
+	return days
+}
+
+// buildCalendar collects upcoming movie releases in region and episode air
+// dates of currently airing shows from today through the next days days.
+func buildCalendar(client *tmdb.Client, region string, days int) ([]tmdb.CalendarDay, error) {
+	now := time.Now()
+	from := now.Format("2006-01-02")
+	to := now.AddDate(0, 0, days).Format("2006-01-02")
+	inWindow := func(date string) bool {
+		return date != "" && date >= from && date <= to
+	}
+
+	var entries []tmdb.CalendarEntry
+	seen := make(map[int]bool)
+	for page := 1; page <= calendarMoviePages; page++ {
+		upcoming, err := client.GetUpcomingMovies(region, page)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range upcoming.Results {
+			if seen[m.ID] || !inWindow(m.ReleaseDate) {
+				continue
+			}
+			seen[m.ID] = true
+			entries = append(entries, tmdb.CalendarEntry{
+				Date:       m.ReleaseDate,
+				MediaType:  "movie",
+				ID:         m.ID,
+				Title:      m.Title,
+				PosterPath: m.PosterPath,
+				Overview:   m.Overview,
+			})
+		}
+		if page >= upcoming.TotalPages {
+			break
+		}
+	}
+
+	shows, err := airingShows(client)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range showEpisodes(client, shows) {
+		if inWindow(e.Date) {
+			entries = append(entries, e)
+		}
+	}
+
+	return tmdb.GroupByDate(entries), nil
+}
+
+// airingShows merges today's and this week's airing lists, most popular
+// first, capped at calendarMaxShows.
+func airingShows(client *tmdb.Client) ([]tmdb.SearchResult, error) {
+	today, err := client.GetAiringTodayTV(1)
+	if err != nil {
+		return nil, err
+	}
+	week, err := client.GetOnTheAirTV(1)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[int]bool)
+	var shows []tmdb.SearchResult
+	for _, s := range append(today.Results, week.Results...) {
+		if !seen[s.ID] {
+			seen[s.ID] = true
+			shows = append(shows, s)
+		}
+	}
+	sort.SliceStable(shows, func(i, j int) bool {
+		return shows[i].Popularity > shows[j].Popularity
+	})
+	if len(shows) > calendarMaxShows {
+		shows = shows[:calendarMaxShows]
+	}
+	return shows, nil
+}
+
+// showEpisodes looks up the last and next episode of each show. Shows
+// whose details fail to load are skipped.
+func showEpisodes(client *tmdb.Client, shows []tmdb.SearchResult) []tmdb.CalendarEntry {
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		sem     = make(chan struct{}, calendarConcurrency)
+		entries []tmdb.CalendarEntry
+	)
+
+	for _, show := range shows {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			tv, err := client.GetTVSummary(id)
+			if err != nil {
+				log.Printf("Calendar TV %d error: %v", id, err)
+				return
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			for _, ep := range []*tmdb.Episode{tv.LastEpisodeToAir, tv.NextEpisodeToAir} {
+				if ep == nil {
+					continue
+				}
+				entries = append(entries, tmdb.CalendarEntry{
+					Date:          ep.AirDate,
+					MediaType:     "tv",
+					ID:            tv.ID,
+					Title:         tv.Name,
+					PosterPath:    tv.PosterPath,
+					Overview:      ep.Overview,
+					SeasonNumber:  ep.SeasonNumber,
+					EpisodeNumber: ep.EpisodeNumber,
+					EpisodeName:   ep.Name,
+				})
+			}
+		}(show.ID)
+	}
+
+	wg.Wait()
+	return entries
+}
@@ -9,23 +9,17 @@ import (
 	"net/url"
 	"strings"
 	"time"
-
-	"github.com/go-chi/chi/v5"
 )
 
 func (h *Handler) DownloadProxy(w http.ResponseWriter, r *http.Request) {
-	tracker := chi.URLParam(r, "tracker")
-	if tracker == "" {
-		http.Error(w, "Tracker is required", http.StatusBadRequest)
+	var params downloadRequest
+	if err := decodeRequest(r, &params); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-
-	query := r.URL.Query()
-	path := query.Get("path")
-	file := query.Get("file")
-
-	if path == "" {
-		http.Error(w, "Path is required", http.StatusBadRequest)
+	tracker, path, file := params.Tracker, params.Path, params.File
+	if !h.fetcher.ValidProxySignature(tracker, path, file, params.Sig) {
+		http.Error(w, "Invalid download link signature", http.StatusForbidden)
 		return
 	}
 
@@ -88,11 +82,12 @@ func (h *Handler) DownloadProxy(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) ResolveLink(w http.ResponseWriter, r *http.Request) {
-	proxyURL := r.URL.Query().Get("url")
-	if proxyURL == "" {
-		writeJSONError(w, "url parameter is required", http.StatusBadRequest)
+	var params resolveLinkRequest
+	if err := decodeRequest(r, &params); err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	proxyURL := params.URL
 
 	if !strings.HasPrefix(proxyURL, "/dl/") {
 		writeJSONError(w, "url must be a /dl/ proxy URL", http.StatusBadRequest)
@@ -119,6 +114,10 @@ func (h *Handler) ResolveLink(w http.ResponseWriter, r *http.Request) {
 		writeJSONError(w, "path is required", http.StatusBadRequest)
 		return
 	}
+	if !h.fetcher.ValidProxySignature(tracker, path, file, query.Get("sig")) {
+		writeJSONError(w, "invalid download link signature", http.StatusForbidden)
+		return
+	}
 
 	jackettURL := fmt.Sprintf(
 		"%s/dl/%s/?jackett_apikey=%s&path=%s",
@@ -161,19 +160,19 @@ func (h *Handler) ResolveLink(w http.ResponseWriter, r *http.Request) {
 	if finalURL != "" {
 		log.Printf("Resolve link: resolved to %s", finalURL)
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]string{"url": finalURL})
+		json.NewEncoder(w).Encode(resolveLinkResponse{URL: finalURL})
 		return
 	}
 
 	if resp.StatusCode >= 300 && resp.StatusCode < 400 {
 		if loc := resp.Header.Get("Location"); loc != "" {
 			w.Header().Set("Content-Type", "application/json")
-			json.NewEncoder(w).Encode(map[string]string{"url": loc})
+			json.NewEncoder(w).Encode(resolveLinkResponse{URL: loc})
 			return
 		}
 	}
 
 	log.Printf("Resolve link: no redirect found, status %d", resp.StatusCode)
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"url": jackettURL})
+	json.NewEncoder(w).Encode(resolveLinkResponse{URL: jackettURL})
 }
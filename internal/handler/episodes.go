@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/unedtamps/orbit/internal/fansub"
+)
+
+// Match labels, reported on hits that cover the requested episode in a
+// way the release name alone does not make obvious.
+const (
+	MatchMultiEpisode = "multi-episode"
+	MatchSeasonPack   = "season pack"
+	MatchBatch        = "batch"
+	MatchAirDate      = "air date"
+	MatchSpecial      = "special"
+)
+
+var (
+	// seasonEpisodeRe matches S01E02 and the extra episodes of
+	// multi-episode files: S01E01E02, S01E01-E03 and S01E01-03.
+	seasonEpisodeRe = regexp.MustCompile(`(?i)\bs(\d{1,2})[ ._-]?e(\d{1,4})((?:-?e\d{1,4}|-\d{1,4}\b)*)`)
+	// crossEpisodeRe matches 1x02, 1x01-1x02 and 1x01-02.
+	crossEpisodeRe = regexp.MustCompile(`(?i)\b(\d{1,2})x(\d{2,3})(?:-(?:\d{1,2}x)?(\d{2,3}))?\b`)
+	// seasonPackRe matches S01, S01-S03 and "Season 1" with no episode.
+	seasonPackRe = regexp.MustCompile(`(?i)\bs(\d{1,2})(?:-s?(\d{1,2}))?\b|\bseason[ ._]?(\d{1,2})\b`)
+	airDateRe    = regexp.MustCompile(`\b(\d{4})[ ._-](\d{2})[ ._-](\d{2})\b`)
+	// absoluteRe matches an episode number written as " - 27", "[27]",
+	// "(27)", "#27" or "EP 27". Bare numbers between dots or spaces are
+	// left alone: they are as often "AAC 5.1" or "H 264".
+	absoluteRe = regexp.MustCompile(`(?i)(?:\s-\s|[\[(#]|\bep(?:isode)?[ ._]?)0*(\d{1,4})(?:v\d)?(?:[ ._\-\])]|$)`)
+	digitsRe   = regexp.MustCompile(`\d+`)
+)
+
+// maxSpan bounds the episodes one release may claim, so a resolution or
+// year after an episode code is never read as the end of a range.
+const maxSpan = 50
+
+// episodeSpan is the run of episodes in one season a release covers.
+type episodeSpan struct {
+	Season, First, Last int
+}
+
+func (s episodeSpan) covers(season, episode int) bool {
+	return s.Season == season && episode >= s.First && episode <= s.Last
+}
+
+// parseEpisodeSpan reads the episode code of a release name, if any.
+func parseEpisodeSpan(release string) (episodeSpan, bool) {
+	if m := seasonEpisodeRe.FindStringSubmatch(release); m != nil {
+		s := episodeSpan{Season: atoi(m[1]), First: atoi(m[2])}
+		s.Last = s.First
+		for _, n := range digitsRe.FindAllString(m[3], -1) {
+			if e := atoi(n); e > s.Last && e-s.First <= maxSpan {
+				s.Last = e
+			}
+		}
+		return s, true
+	}
+	if m := crossEpisodeRe.FindStringSubmatch(release); m != nil {
+		s := episodeSpan{Season: atoi(m[1]), First: atoi(m[2])}
+		s.Last = s.First
+		if e := atoi(m[3]); e > s.Last && e-s.First <= maxSpan {
+			s.Last = e
+		}
+		return s, true
+	}
+	return episodeSpan{}, false
+}
+
+// seasonPack reports whether a release with no episode code is a pack
+// that includes the season.
+func seasonPack(release string, season int) bool {
+	m := seasonPackRe.FindStringSubmatch(release)
+	if m == nil {
+		return false
+	}
+	if m[3] != "" {
+		return atoi(m[3]) == season
+	}
+	first := atoi(m[1])
+	last := first
+	if m[2] != "" {
+		last = atoi(m[2])
+	}
+	return season >= first && season <= last
+}
+
+// airedOn reports whether a release is dated like a daily show, as
+// 2024.05.01, on the given YYYY-MM-DD air date.
+func airedOn(release, airDate string) bool {
+	for _, m := range airDateRe.FindAllStringSubmatch(release, -1) {
+		if m[1]+"-"+m[2]+"-"+m[3] == airDate {
+			return true
+		}
+	}
+	return false
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// match reports whether a release covers the episode and, when how it
+// does is worth pointing out, a label for it. Releases carrying another
+// episode's code never match.
+func (t episodeTarget) match(release string) (label string, ok bool) {
+	if span, found := parseEpisodeSpan(release); found {
+		switch {
+		case !span.covers(t.Season, t.Episode):
+			return "", false
+		case span.Last > span.First:
+			return fmt.Sprintf("%s E%02d-E%02d", MatchMultiEpisode, span.First, span.Last), true
+		case t.Season == 0:
+			return MatchSpecial, true
+		}
+		return "", true
+	}
+
+	if t.AirDate != "" && airedOn(release, t.AirDate) {
+		return MatchAirDate, true
+	}
+	if r, found := fansub.Parse(release); found {
+		if !fansubCovers(r, t) {
+			return "", false
+		}
+		if r.Batch() {
+			return fmt.Sprintf("%s %02d-%02d", MatchBatch, r.Episode, r.LastEpisode), true
+		}
+		return "", true
+	}
+	if t.EpisodeTitle != "" && strings.Contains(slugify(releaseWords(release)), slugify(t.EpisodeTitle)) {
+		if t.Season == 0 {
+			return MatchSpecial, true
+		}
+		return "", true
+	}
+	if seasonPackRe.MatchString(release) {
+		if t.Season > 0 && seasonPack(release, t.Season) {
+			return MatchSeasonPack, true
+		}
+		return "", false
+	}
+	if t.Absolute > 0 {
+		for _, m := range absoluteRe.FindAllStringSubmatch(release, -1) {
+			if atoi(m[1]) == t.Absolute {
+				return "", true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,79 @@
+package handler
+
+import "testing"
+
+func TestEpisodeTargetMatch(t *testing.T) {
+	s01e02 := episodeTarget{Show: "Show", Season: 1, Episode: 2}
+	tests := []struct {
+		name      string
+		target    episodeTarget
+		release   string
+		wantLabel string
+		wantOK    bool
+	}{
+		{"exact", s01e02, "Show.S01E02.1080p.WEB.h264-GRP", "", true},
+		{"spaced code", s01e02, "Show S01 E02 720p", "", true},
+		{"other episode", s01e02, "Show.S01E03.1080p.WEB.h264-GRP", "", false},
+		{"other season", s01e02, "Show.S02E02.1080p", "", false},
+		{"double episode", s01e02, "Show.S01E01E02.1080p.WEB", "multi-episode E01-E02", true},
+		{"double episode, other", s01e02, "Show.S01E03E04.1080p.WEB", "", false},
+		{"episode range", s01e02, "Show.S01E01-E03.1080p", "multi-episode E01-E03", true},
+		{"short episode range", s01e02, "Show.S01E01-03.1080p", "multi-episode E01-E03", true},
+		{"resolution is no range end", episodeTarget{Season: 1, Episode: 1}, "Show.S01E01-1080p", "", true},
+		{"cross code", s01e02, "Show.1x02.HDTV", "", true},
+		{"cross range", s01e02, "Show.1x01-1x03.HDTV", "multi-episode E01-E03", true},
+		{"season pack", s01e02, "Show.S01.1080p.BluRay", "season pack", true},
+		{"season range pack", s01e02, "Show.S01-S03.Complete.1080p", "season pack", true},
+		{"season word pack", s01e02, "Show Season 1 Complete", "season pack", true},
+		{"other season pack", s01e02, "Show.S02.1080p.BluRay", "", false},
+		{"no code", s01e02, "Show.1080p.WEB", "", false},
+
+		{"special by code", episodeTarget{Season: 0, Episode: 3}, "Show.S00E03.Christmas.Special.720p", "special", true},
+		{"special by title", episodeTarget{Season: 0, Episode: 3, EpisodeTitle: "The Christmas Invasion"}, "Show.The.Christmas.Invasion.720p", "special", true},
+		{"special, other title", episodeTarget{Season: 0, Episode: 3, EpisodeTitle: "The Christmas Invasion"}, "Show.The.Runaway.Bride.720p", "", false},
+		{"specials are no season pack", episodeTarget{Season: 0, Episode: 3}, "Show.S00.Specials.720p", "", false},
+		{"episode title", episodeTarget{Season: 2, Episode: 5, EpisodeTitle: "Ozymandias"}, "Show.Ozymandias.1080p", "", true},
+
+		{"air date", episodeTarget{Season: 2024, Episode: 61, AirDate: "2024-05-01"}, "Late.Show.2024.05.01.Guest.720p.WEB", "air date", true},
+		{"air date, dashes", episodeTarget{Season: 2024, Episode: 61, AirDate: "2024-05-01"}, "Late Show 2024-05-01 720p", "air date", true},
+		{"other air date", episodeTarget{Season: 2024, Episode: 61, AirDate: "2024-05-01"}, "Late.Show.2024.05.02.Guest.720p.WEB", "", false},
+
+		{"fansub episode", episodeTarget{Season: 1, Episode: 7, Anime: true}, "[SubsPlease] Show - 07 (1080p) [ABCD1234].mkv", "", true},
+		{"fansub other episode", episodeTarget{Season: 1, Episode: 7, Anime: true}, "[SubsPlease] Show - 08 (1080p) [ABCD1234].mkv", "", false},
+		{"fansub absolute", episodeTarget{Season: 2, Episode: 3, Absolute: 27, Anime: true}, "[SubsPlease] Show - 27 (1080p) [ABCD1234].mkv", "", true},
+		{"absolute after a dash", episodeTarget{Season: 2, Episode: 3, Absolute: 27}, "Show - 027 [1080p]", "", true},
+		{"absolute episode word", episodeTarget{Season: 2, Episode: 3, Absolute: 27}, "Show EP27 1080p WEB", "", true},
+		{"audio channels are no episode", episodeTarget{Season: 1, Episode: 5, Absolute: 5}, "Show 1080p WEB-DL AAC 5.1 H 264-GRP", "", false},
+		{"stereo is no episode", episodeTarget{Season: 1, Episode: 2, Absolute: 2}, "Show.1080p.WEB.DDP.2.0.x264", "", false},
+		{"codec is no episode", episodeTarget{Season: 10, Episode: 4, Absolute: 264}, "Show 1080p WEB H 264-GRP", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			label, ok := tt.target.match(tt.release)
+			if label != tt.wantLabel || ok != tt.wantOK {
+				t.Errorf("match(%q) = %q, %v; want %q, %v", tt.release, label, ok, tt.wantLabel, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestParseEpisodeSpan(t *testing.T) {
+	tests := []struct {
+		release string
+		want    episodeSpan
+		wantOK  bool
+	}{
+		{"Show.S01E02.1080p", episodeSpan{1, 2, 2}, true},
+		{"Show.S01E01E02E03.1080p", episodeSpan{1, 1, 3}, true},
+		{"Show.S01E01-E03.1080p", episodeSpan{1, 1, 3}, true},
+		{"Show.s03e10-12.720p", episodeSpan{3, 10, 12}, true},
+		{"Show.2x05.HDTV", episodeSpan{2, 5, 5}, true},
+		{"Show.S01.1080p", episodeSpan{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseEpisodeSpan(tt.release)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("parseEpisodeSpan(%q) = %+v, %v; want %+v, %v", tt.release, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
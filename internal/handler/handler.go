@@ -9,21 +9,34 @@ import (
 	"strings"
 
 	"github.com/unedtamps/orbit/internal/fetcher"
+	"github.com/unedtamps/orbit/internal/imagecache"
+	"github.com/unedtamps/orbit/internal/imdb"
+	"github.com/unedtamps/orbit/internal/metadata"
 	"github.com/unedtamps/orbit/internal/tmdb"
 )
 
 type Handler struct {
 	fetcher  *fetcher.Fetcher
 	tmdb     *tmdb.Client
+	meta     *metadata.Chain
+	imdb     *imdb.Store
 	template *template.Template
+	prefs    Preferences
 }
 
-func New(f *fetcher.Fetcher, tm *tmdb.Client, tmpl *template.Template) *Handler {
-	return &Handler{fetcher: f, tmdb: tm, template: tmpl}
+func New(f *fetcher.Fetcher, tm *tmdb.Client, meta *metadata.Chain, store *imdb.Store, tmpl *template.Template, prefs Preferences) *Handler {
+	return &Handler{fetcher: f, tmdb: tm, meta: meta, imdb: store, template: tmpl, prefs: prefs}
 }
 
-func LoadTemplates(glob string) *template.Template {
+func LoadTemplates(glob string, images imagecache.URLs) *template.Template {
 	funcMap := template.FuncMap{
+		"img": images.URL,
+		// imgBase is concatenated into Alpine :src bindings, which
+		// html/template treats as URLs and would otherwise escape.
+		"imgBase": func() template.URL {
+			return template.URL(images.Base())
+		},
+		"srcset": images.Srcset,
 		"safeURL": func(u string) template.URL {
 			return template.URL(u)
 		},
@@ -60,6 +73,21 @@ func LoadTemplates(glob string) *template.Template {
 			}
 			return v.Slice(0, n).Interface()
 		},
+		// dict builds the data of a partial from key/value pairs.
+		"dict": func(kv ...interface{}) (map[string]interface{}, error) {
+			if len(kv)%2 != 0 {
+				return nil, fmt.Errorf("dict: odd number of arguments")
+			}
+			m := make(map[string]interface{}, len(kv)/2)
+			for i := 0; i < len(kv); i += 2 {
+				k, ok := kv[i].(string)
+				if !ok {
+					return nil, fmt.Errorf("dict: key %v is not a string", kv[i])
+				}
+				m[k] = kv[i+1]
+			}
+			return m, nil
+		},
 	}
 
 	tmpl, err := template.New("").Funcs(funcMap).ParseGlob(glob)
@@ -0,0 +1,246 @@
+package handler
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/unedtamps/orbit/internal/ical"
+	"github.com/unedtamps/orbit/internal/tmdb"
+)
+
+const (
+	// feedMaxTitles caps how many titles a combined feed may request.
+	feedMaxTitles = 20
+	// feedConcurrency bounds parallel TMDB requests while building a feed.
+	feedConcurrency = 4
+	// uidDomain scopes event UIDs so they never collide with other feeds.
+	uidDomain = "orbitsearch"
+)
+
+type ICalHandler struct {
+	client  *tmdb.Client
+	prefs   Preferences
+	hostURL string
+}
+
+func NewICalHandler(client *tmdb.Client, prefs Preferences, hostURL string) *ICalHandler {
+	return &ICalHandler{client: client, prefs: prefs, hostURL: strings.TrimRight(hostURL, "/")}
+}
+
+// TVFeed serves every dated episode of a show.
+func (h *ICalHandler) TVFeed(w http.ResponseWriter, r *http.Request) {
+	var req idRequest
+	if err := decodeRequest(r, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	id := req.ID
+
+	name, events, err := h.tvEvents(id)
+	if err != nil {
+		log.Printf("iCal TV %d error: %v", id, err)
+		http.Error(w, fmt.Sprintf("Failed to fetch TV show: %v", err), http.StatusBadGateway)
+		return
+	}
+
+	writeCalendar(w, fmt.Sprintf("tv-%d.ics", id), ical.Calendar{Name: name, Events: events})
+}
+
+// MovieFeed serves a movie's release dates in the user's region.
+func (h *ICalHandler) MovieFeed(w http.ResponseWriter, r *http.Request) {
+	var req idRegionRequest
+	if err := decodeRequest(r, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	id := req.ID
+
+	name, events, err := h.movieEvents(id, h.prefs.resolve(r).Region)
+	if err != nil {
+		log.Printf("iCal movie %d error: %v", id, err)
+		http.Error(w, fmt.Sprintf("Failed to fetch movie: %v", err), http.StatusBadGateway)
+		return
+	}
+
+	writeCalendar(w, fmt.Sprintf("movie-%d.ics", id), ical.Calendar{Name: name, Events: events})
+}
+
+// CombinedFeed merges the feeds of ?tv=1,2&movie=3 into one calendar.
+// Titles that fail to load are left out rather than failing the feed.
+func (h *ICalHandler) CombinedFeed(w http.ResponseWriter, r *http.Request) {
+	var req feedRequest
+	if err := decodeRequest(r, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	tvIDs, movieIDs := req.TV, req.Movie
+	for _, ids := range [][]int{tvIDs, movieIDs} {
+		for _, id := range ids {
+			if id < 1 {
+				http.Error(w, fmt.Sprintf("Invalid id %d", id), http.StatusBadRequest)
+				return
+			}
+		}
+	}
+	if len(tvIDs)+len(movieIDs) == 0 {
+		http.Error(w, "tv or movie parameter is required", http.StatusBadRequest)
+		return
+	}
+	if len(tvIDs)+len(movieIDs) > feedMaxTitles {
+		http.Error(w, fmt.Sprintf("At most %d titles per feed", feedMaxTitles), http.StatusBadRequest)
+		return
+	}
+	region := h.prefs.resolve(r).Region
+
+	type job struct {
+		mediaType string
+		id        int
+	}
+	var jobs []job
+	for _, id := range tvIDs {
+		jobs = append(jobs, job{"tv", id})
+	}
+	for _, id := range movieIDs {
+		jobs = append(jobs, job{"movie", id})
+	}
+
+	results := make([][]ical.Event, len(jobs))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, feedConcurrency)
+	for i, j := range jobs {
+		wg.Add(1)
+		go func(i int, j job) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			var events []ical.Event
+			var err error
+			if j.mediaType == "movie" {
+				_, events, err = h.movieEvents(j.id, region)
+			} else {
+				_, events, err = h.tvEvents(j.id)
+			}
+			if err != nil {
+				log.Printf("iCal combined feed: skipping %s %d: %v", j.mediaType, j.id, err)
+				return
+			}
+			results[i] = events
+		}(i, j)
+	}
+	wg.Wait()
+
+	var events []ical.Event
+	for _, e := range results {
+		events = append(events, e...)
+	}
+	writeCalendar(w, "orbit.ics", ical.Calendar{Name: "OrbitSearch Releases", Events: events})
+}
+
+// tvEvents loads every season of a show and returns its dated episodes.
+// Episode UIDs use TMDB's episode ID, which survives renumbering.
+func (h *ICalHandler) tvEvents(id int) (string, []ical.Event, error) {
+	tv, err := h.client.GetTVSummary(id)
+	if err != nil {
+		return "", nil, err
+	}
+
+	seasons := make([]*tmdb.SeasonDetails, len(tv.Seasons))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, feedConcurrency)
+	for i, s := range tv.Seasons {
+		wg.Add(1)
+		go func(i, number int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			season, err := h.client.GetSeasonDetails(id, number)
+			if err != nil {
+				log.Printf("iCal TV %d season %d error: %v", id, number, err)
+				return
+			}
+			seasons[i] = season
+		}(i, s.SeasonNumber)
+	}
+	wg.Wait()
+
+	var events []ical.Event
+	for _, season := range seasons {
+		if season == nil {
+			continue
+		}
+		for _, ep := range season.Episodes {
+			date, err := time.Parse("2006-01-02", ep.AirDate)
+			if err != nil {
+				continue
+			}
+			summary := fmt.Sprintf("%s S%02dE%02d", tv.Name, ep.SeasonNumber, ep.EpisodeNumber)
+			if ep.Name != "" {
+				summary += ": " + ep.Name
+			}
+			events = append(events, ical.Event{
+				UID:         fmt.Sprintf("tmdb-episode-%d@%s", ep.ID, uidDomain),
+				Date:        date,
+				Summary:     summary,
+				Description: ep.Overview,
+				URL:         fmt.Sprintf("%s/tv/%d/season/%d", h.hostURL, id, ep.SeasonNumber),
+			})
+		}
+	}
+	return tv.Name, events, nil
+}
+
+// movieEvents returns one event per release type in region, using the
+// earliest date of each type. Movies without regional data fall back to
+// TMDB's primary release date.
+func (h *ICalHandler) movieEvents(id int, region string) (string, []ical.Event, error) {
+	movie, err := h.client.GetMovieSummary(id)
+	if err != nil {
+		return "", nil, err
+	}
+	url := fmt.Sprintf("%s/movie/%d", h.hostURL, id)
+
+	var events []ical.Event
+	if movie.ReleaseDates != nil {
+		seen := make(map[tmdb.ReleaseType]bool)
+		for _, rd := range movie.ReleaseDates.Region(region) {
+			date, err := time.Parse("2006-01-02", rd.Date())
+			if err != nil || seen[rd.Type] {
+				continue
+			}
+			seen[rd.Type] = true
+			events = append(events, ical.Event{
+				UID:         fmt.Sprintf("tmdb-movie-%d-%s-%d@%s", id, strings.ToLower(region), rd.Type, uidDomain),
+				Date:        date,
+				Summary:     fmt.Sprintf("%s (%s release)", movie.Title, rd.Type),
+				Description: movie.Overview,
+				URL:         url,
+			})
+		}
+	}
+	if len(events) == 0 {
+		if date, err := time.Parse("2006-01-02", movie.ReleaseDate); err == nil {
+			events = append(events, ical.Event{
+				UID:         fmt.Sprintf("tmdb-movie-%d@%s", id, uidDomain),
+				Date:        date,
+				Summary:     movie.Title + " (release)",
+				Description: movie.Overview,
+				URL:         url,
+			})
+		}
+	}
+	return movie.Title, events, nil
+}
+
+func writeCalendar(w http.ResponseWriter, filename string, cal ical.Calendar) {
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", filename))
+	if _, err := cal.WriteTo(w); err != nil {
+		log.Printf("iCal write error: %v", err)
+	}
+}
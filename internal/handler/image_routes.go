@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/unedtamps/orbit/internal/imagecache"
+)
+
+type ImageHandler struct {
+	cache *imagecache.Cache
+}
+
+func NewImageHandler(cache *imagecache.Cache) *ImageHandler {
+	return &ImageHandler{cache: cache}
+}
+
+// svgPolicy stops scripts and external loads in proxied SVGs. TMDB logos
+// are user uploads served from Orbit's own origin.
+const svgPolicy = "default-src 'none'; style-src 'unsafe-inline'; sandbox"
+
+// Serve proxies a TMDB image through the disk cache. TMDB image paths never
+// change content, so responses are cacheable for a year.
+func (h *ImageHandler) Serve(w http.ResponseWriter, r *http.Request) {
+	size := chi.URLParam(r, "size")
+	path := "/" + chi.URLParam(r, "*")
+
+	img, err := h.cache.Get(r.Context(), size, path)
+	if err != nil {
+		switch {
+		case errors.Is(err, imagecache.ErrInvalidSize), errors.Is(err, imagecache.ErrInvalidPath):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, imagecache.ErrNotFound):
+			http.Error(w, "Image not found", http.StatusNotFound)
+		default:
+			log.Printf("Image proxy error: %v", err)
+			http.Error(w, "Failed to fetch image", http.StatusBadGateway)
+		}
+		return
+	}
+
+	f, err := os.Open(img.Path)
+	if err != nil {
+		log.Printf("Image proxy: failed to open %s: %v", img.Path, err)
+		http.Error(w, "Failed to read image", http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if strings.HasSuffix(path, ".svg") {
+		w.Header().Set("Content-Security-Policy", svgPolicy)
+	}
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("ETag", img.ETag)
+	http.ServeContent(w, r, path, img.ModTime, f)
+}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/unedtamps/orbit/internal/imagecache"
+)
+
+func TestImageHeaders(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "original"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"logo.svg":   `<svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script></svg>`,
+		"poster.png": "\x89PNG\r\n\x1a\n",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, "original", name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cache, err := imagecache.New(dir, 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := chi.NewRouter()
+	r.Get("/img/{size}/*", NewImageHandler(cache).Serve)
+
+	tests := []struct {
+		path string
+		csp  string
+	}{
+		{"/img/original/logo.svg", svgPolicy},
+		{"/img/original/poster.png", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+			if w.Code != 200 {
+				t.Fatalf("status = %d", w.Code)
+			}
+			if got := w.Header().Get("Content-Security-Policy"); got != tt.csp {
+				t.Errorf("Content-Security-Policy = %q, want %q", got, tt.csp)
+			}
+			if got := w.Header().Get("X-Content-Type-Options"); got != "nosniff" {
+				t.Errorf("X-Content-Type-Options = %q, want nosniff", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"github.com/unedtamps/orbit/internal/imdb"
+	"github.com/unedtamps/orbit/internal/tmdb"
+)
+
+// imdbRating validates an IMDb ID that TMDB supplied against the dataset
+// and returns it with its rating. The ID is "" when the dataset does not
+// know it or knows it as the wrong kind of title.
+func imdbRating(store *imdb.Store, id string, check func(imdb.Title) bool) (string, *imdb.Rating) {
+	if id == "" || !store.Valid(id, check) {
+		return "", nil
+	}
+	return id, store.Rating(id)
+}
+
+func movieIMDb(store *imdb.Store, movie *tmdb.MovieDetails) (string, *imdb.Rating) {
+	return imdbRating(store, movie.IMDbID, imdb.Title.IsMovie)
+}
+
+func tvIMDb(store *imdb.Store, tv *tmdb.TVDetails) (string, *imdb.Rating) {
+	if tv.ExternalIDs == nil {
+		return "", nil
+	}
+	return imdbRating(store, tv.ExternalIDs.IMDbID, imdb.Title.IsSeries)
+}
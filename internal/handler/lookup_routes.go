@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/unedtamps/orbit/internal/imdb"
+	"github.com/unedtamps/orbit/internal/lookup"
+	"github.com/unedtamps/orbit/internal/tmdb"
+)
+
+// LookupHandler redirects IDs and links from other sites to Orbit pages.
+type LookupHandler struct {
+	client   *tmdb.Client
+	resolver *lookup.Resolver
+	imdb     *imdb.Store
+}
+
+func NewLookupHandler(client *tmdb.Client, store *imdb.Store) *LookupHandler {
+	return &LookupHandler{client: client, resolver: lookup.NewResolver(), imdb: store}
+}
+
+// IMDb redirects /imdb/{tt} to the matching movie, show, person or episode.
+func (h *LookupHandler) IMDb(w http.ResponseWriter, r *http.Request) {
+	var req externalIDRequest
+	if err := decodeRequest(r, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	id := req.ID
+	if !lookup.IsIMDbID(id) {
+		http.Error(w, "Invalid IMDb ID", http.StatusBadRequest)
+		return
+	}
+	// The dataset only covers titles; names always go to TMDB.
+	if strings.HasPrefix(id, "tt") && !h.imdb.Valid(id, nil) {
+		http.Error(w, "Unknown IMDb title", http.StatusNotFound)
+		return
+	}
+	h.redirectFind(w, r, id, tmdb.SourceIMDb)
+}
+
+// TVDB redirects /tvdb/{id} to the matching show or episode.
+func (h *LookupHandler) TVDB(w http.ResponseWriter, r *http.Request) {
+	var req externalIDRequest
+	if err := decodeRequest(r, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	id := req.ID
+	if n, err := strconv.Atoi(id); err != nil || n < 1 {
+		http.Error(w, "Invalid TVDB ID", http.StatusBadRequest)
+		return
+	}
+	h.redirectFind(w, r, id, tmdb.SourceTVDB)
+}
+
+// Lookup redirects ?url= pointing at IMDb, TMDB, Letterboxd or Trakt.
+func (h *LookupHandler) Lookup(w http.ResponseWriter, r *http.Request) {
+	var req lookupRequest
+	if err := decodeRequest(r, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	raw := req.URL
+
+	target, err := h.resolver.Resolve(r.Context(), raw)
+	if err != nil {
+		switch {
+		case errors.Is(err, lookup.ErrUnsupported):
+			http.Error(w, "Unsupported link: use an IMDb, TMDB, Letterboxd or Trakt URL", http.StatusBadRequest)
+		case errors.Is(err, lookup.ErrNotFound):
+			http.Error(w, "No TMDB or IMDb reference found on that page", http.StatusNotFound)
+		default:
+			log.Printf("Lookup error for %q: %v", raw, err)
+			http.Error(w, "Failed to fetch that page", http.StatusBadGateway)
+		}
+		return
+	}
+
+	if target.TMDBID != "" {
+		http.Redirect(w, r, target.Path(), http.StatusFound)
+		return
+	}
+	h.redirectFind(w, r, target.ExternalID, target.Source)
+}
+
+func (h *LookupHandler) redirectFind(w http.ResponseWriter, r *http.Request, id, source string) {
+	result, err := h.client.Find(id, source)
+	if err != nil {
+		log.Printf("TMDB find error: %v", err)
+		http.Error(w, cleanTMDBError(err), http.StatusBadGateway)
+		return
+	}
+
+	path := result.Path()
+	if path == "" {
+		http.Error(w, "Nothing on TMDB matches "+id, http.StatusNotFound)
+		return
+	}
+	http.Redirect(w, r, path, http.StatusFound)
+}
@@ -1,15 +1,24 @@
 package handler
 
 import (
+	"context"
 	"html/template"
 	"log"
 	"net/http"
+	"net/url"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 	"unicode"
 
 	"github.com/unedtamps/orbit/internal/fetcher"
+	"github.com/unedtamps/orbit/internal/model"
+	"github.com/unedtamps/orbit/internal/tmdb"
+	"github.com/unedtamps/orbit/internal/translit"
+	"github.com/unedtamps/orbit/internal/ttlcache"
 
 	jackett "github.com/webtor-io/go-jackett"
 	"golang.org/x/text/transform"
@@ -18,6 +27,9 @@ import (
 
 var multiHyphen = regexp.MustCompile(`-{2,}`)
 
+// packPattern matches release names of multi-film packs.
+var packPattern = regexp.MustCompile(`(?i)\b(collection|trilogy|quadrilogy|pentalogy|hexalogy|duology|saga|anthology|complete|box[ .-]?set)\b`)
+
 var stripCombining = transform.Chain(
 	norm.NFD,
 	transform.RemoveFunc(func(r rune) bool {
@@ -31,7 +43,10 @@ func normalizeUnicode(s string) string {
 	return result
 }
 
+// slugify turns a title into a hyphenated Latin query, romanizing
+// Cyrillic, Greek, kana and Han first so they don't vanish.
 func slugify(s string) string {
+	s, _ = translit.Latin(s)
 	s = normalizeUnicode(s)
 	s = strings.ReplaceAll(s, "ß", "ss")
 	s = strings.Map(func(r rune) rune {
@@ -48,6 +63,58 @@ func slugify(s string) string {
 	return s
 }
 
+// nativeSlug is slugify without romanization, for trackers such as rutor
+// that index titles in their own script.
+func nativeSlug(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' {
+			return r
+		}
+		if r == ' ' || r == '_' {
+			return '-'
+		}
+		return -1
+	}, strings.ToLower(s))
+	s = multiHyphen.ReplaceAllString(s, "-")
+	return strings.Trim(s, "-")
+}
+
+// titleVariants returns the distinct slugs worth searching for a set of
+// titles, typically the display title and the original one. Titles in a
+// non-Latin script contribute their native slug and, when it could be
+// fully romanized, their transliteration.
+func titleVariants(titles ...string) []string {
+	var variants []string
+	seen := make(map[string]bool)
+	add := func(slug string) {
+		if slug != "" && !seen[slug] {
+			seen[slug] = true
+			variants = append(variants, slug)
+		}
+	}
+	for _, t := range titles {
+		if !translit.NonLatin(t) {
+			add(slugify(t))
+			continue
+		}
+		add(nativeSlug(t))
+		if _, ok := translit.Latin(t); ok {
+			add(slugify(t))
+		}
+	}
+	return variants
+}
+
+// sortBySeeders orders results by seeders, then peers, as the fetcher does.
+func sortBySeeders(results []jackett.Result) {
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Seeders != results[j].Seeders {
+			return results[i].Seeders > results[j].Seeders
+		}
+		return results[i].Peers > results[j].Peers
+	})
+}
+
 func dedupe(a, b []jackett.Result) []jackett.Result {
 	seen := make(map[string]int, len(a))
 	merged := make([]jackett.Result, 0, len(a)+len(b))
@@ -80,88 +147,511 @@ func dedupeKey(r jackett.Result) string {
 	return strings.ToLower(r.Title)
 }
 
+// batchConcurrency caps how many Jackett searches a batch lookup runs at once.
+const batchConcurrency = 4
+
 type MagnetHandler struct {
 	fetcher  *fetcher.Fetcher
+	tmdb     *tmdb.Client
 	template *template.Template
+	prefs    Preferences
+
+	availability *ttlcache.Cache[[]MagnetHit]
+	hits         *ttlcache.Cache[[]MagnetHit]
+}
+
+func NewMagnetHandler(f *fetcher.Fetcher, tm *tmdb.Client, tmpl *template.Template, prefs Preferences) *MagnetHandler {
+	return &MagnetHandler{
+		fetcher:      f,
+		tmdb:         tm,
+		template:     tmpl,
+		prefs:        prefs,
+		availability: ttlcache.New[[]MagnetHit](availabilityTTL, availabilityCacheSize),
+		hits:         ttlcache.New[[]MagnetHit](hitTTL, hitCacheSize),
+	}
+}
+
+func (h *MagnetHandler) ratings(r *http.Request) *ratingFilter {
+	return newRatingFilter(h.tmdb, h.prefs, h.prefs.resolve(r))
+}
+
+func movieQuery(title, year string) string {
+	return withYear(slugify(title), year)
+}
+
+func withYear(slug, year string) string {
+	if year != "" && len(year) >= 4 {
+		slug = slug + "-" + year[:4]
+	}
+	return slug
+}
+
+type batchQuery struct {
+	ID    int
+	Title string
+	Year  string
+}
+
+// BatchResult is the outcome of one movie lookup in a batch search.
+type BatchResult struct {
+	ID    int            `json:"id"`
+	Title string         `json:"title"`
+	Year  string         `json:"year"`
+	Best  *model.Release `json:"best"`
+	Count int            `json:"count"`
+	Error string         `json:"error,omitempty"`
+}
+
+// BatchResponse is rendered by magnet_batch_results.html. Packs holds
+// releases bundling several films, when the batch was a collection.
+type BatchResponse struct {
+	Results []BatchResult   `json:"results"`
+	Packs   []model.Release `json:"packs"`
 }
 
-func NewMagnetHandler(f *fetcher.Fetcher, tmpl *template.Template) *MagnetHandler {
-	return &MagnetHandler{fetcher: f, template: tmpl}
+// bestMatch returns the top-ranked result whose title contains the film
+// title, or nil when none does. Results are expected to be sorted
+// already.
+func bestMatch(results []jackett.Result, title string) *jackett.Result {
+	want := slugify(title)
+	for i := range results {
+		if strings.Contains(slugify(releaseWords(results[i].Title)), want) {
+			return &results[i]
+		}
+	}
+	return nil
+}
+
+// releaseWords turns scene separators into spaces so release names can be
+// slugified like titles.
+func releaseWords(s string) string {
+	return strings.NewReplacer(".", " ", "_", " ", "[", " ", "]", " ", "(", " ", ")", " ").Replace(s)
+}
+
+// searchBatch runs the movie magnet search for every query concurrently and
+// keeps the top-ranked release of each that passes f. Results keep the
+// order of queries.
+func (h *MagnetHandler) searchBatch(ctx context.Context, f *ratingFilter, queries []batchQuery) []BatchResult {
+	results := make([]BatchResult, len(queries))
+	sem := make(chan struct{}, batchConcurrency)
+	var wg sync.WaitGroup
+
+	for i, q := range queries {
+		wg.Add(1)
+		go func(i int, q batchQuery) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			res := BatchResult{ID: q.ID, Title: q.Title, Year: q.Year}
+			found, err := h.fetcher.Search(ctx, movieQuery(q.Title, q.Year))
+			if err != nil {
+				log.Printf("Batch magnet search error (%s): %v", q.Title, err)
+				res.Error = "search failed"
+			} else {
+				found = f.releases(found)
+				if match := bestMatch(found, q.Title); match != nil {
+					best := model.FromJackett(*match)
+					res.Best = &best
+					res.Count = len(found)
+				}
+			}
+			results[i] = res
+		}(i, q)
+	}
+
+	wg.Wait()
+	return results
 }
 
+// GetMovieMagnets renders a movie's releases. With ?stream=1 it renders
+// a shell that streams them from GetMovieMagnetStream instead.
 func (h *MagnetHandler) GetMovieMagnets(w http.ResponseWriter, r *http.Request) {
-	title := r.URL.Query().Get("title")
-	year := r.URL.Query().Get("year")
-	if title == "" {
-		http.Error(w, "title parameter is required", http.StatusBadRequest)
+	target, movie, q, ok := h.movieRequest(w, r)
+	if !ok {
 		return
 	}
 
-	query := slugify(title)
-	if year != "" && len(year) >= 4 {
-		query = query + "-" + year[:4]
+	warning := preReleaseSchedule(movie)
+	if streamRequested(r) && !wantsJSON(r) {
+		h.writeStreamShell(w, r, warning)
+		return
 	}
 
-	log.Printf("Magnet search: %q", query)
-	results, err := h.fetcher.Search(r.Context(), query)
+	hits, err := h.cachedRun(r, moviePlan(target))
 	if err != nil {
 		log.Printf("Magnet search error: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.writeResults(w, r, hits, q, warning)
+}
+
+// GetMovieMagnetStream streams a movie's releases as server-sent events.
+func (h *MagnetHandler) GetMovieMagnetStream(w http.ResponseWriter, r *http.Request) {
+	target, _, _, ok := h.movieRequest(w, r)
+	if !ok {
 		return
 	}
+	h.stream(w, r, moviePlan(target))
+}
+
+// movieRequest reads the movie a magnet request is for and the release
+// query its results are shown with, writing the error itself when ok is
+// false.
+func (h *MagnetHandler) movieRequest(w http.ResponseWriter, r *http.Request) (target movieTarget, movie *tmdb.MovieDetails, q releaseQuery, ok bool) {
+	var req movieMagnetRequest
+	err := decodeRequest(r, &req)
+	if err == nil {
+		q, err = req.query(magnetPerPage)
+	}
+	if err != nil {
+		writeError(w, r, err.Error(), http.StatusBadRequest)
+		return target, nil, q, false
+	}
+
+	target = movieTarget{Title: req.Title, Original: req.Original, Year: req.Year}
+	movie = h.movieSummary(req.ID)
+	if movie != nil {
+		target.IMDbID = movie.IMDbID
+		target.AltNames = movie.SceneTitles(sceneTitleLimit)
+		if target.Original == "" {
+			target.Original = movie.OriginalTitle
+		}
+	}
+	return target, movie, q, true
+}
+
+// movieSummary fetches what the planner and the release warning need.
+// Lookup failures only cost those, never the search, so they return nil.
+func (h *MagnetHandler) movieSummary(id int) *tmdb.MovieDetails {
+	movie, err := h.tmdb.GetMovieTitles(id)
+	if err != nil {
+		log.Printf("Magnet movie lookup error: %v", err)
+		return nil
+	}
+	return movie
+}
 
-	h.writeResults(w, results)
+// preReleaseSchedule returns the movie's release schedule when no digital
+// release exists anywhere yet, or nil.
+func preReleaseSchedule(movie *tmdb.MovieDetails) *tmdb.ReleaseSchedule {
+	if movie == nil {
+		return nil
+	}
+	schedule := movie.Schedule("", time.Now())
+	if !schedule.PreReleaseOnly() {
+		return nil
+	}
+	return schedule
 }
 
+// GetEpisodeMagnets renders an episode's releases. With ?stream=1 it
+// renders a shell that streams them from GetEpisodeMagnetStream instead.
 func (h *MagnetHandler) GetEpisodeMagnets(w http.ResponseWriter, r *http.Request) {
-	showName := r.URL.Query().Get("name")
-	season := r.URL.Query().Get("season")
-	episode := r.URL.Query().Get("episode")
-	episodeTitle := r.URL.Query().Get("title")
-	if showName == "" || season == "" || episode == "" {
-		http.Error(w, "name, season, and episode parameters are required", http.StatusBadRequest)
+	target, q, ok := h.episodeRequest(w, r)
+	if !ok {
+		return
+	}
+	if streamRequested(r) && !wantsJSON(r) {
+		h.writeStreamShell(w, r, nil)
 		return
 	}
 
-	ctx := r.Context()
-
-	query1 := slugify(showName) + "-s" + season + "e" + episode
-	log.Printf("Magnet search (slug): %q", query1)
-	results1, err := h.fetcher.Search(ctx, query1)
+	hits, err := h.cachedRun(r, episodePlan(target))
 	if err != nil {
 		log.Printf("Magnet search error: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	var results []jackett.Result
-	if episodeTitle != "" {
-		query2 := slugify(showName) + "-" + slugify(episodeTitle)
-		log.Printf("Magnet search (title): %q", query2)
-		results2, err := h.fetcher.Search(ctx, query2)
+	h.writeResults(w, r, hits, q, nil)
+}
+
+// GetEpisodeMagnetStream streams an episode's releases as server-sent
+// events.
+func (h *MagnetHandler) GetEpisodeMagnetStream(w http.ResponseWriter, r *http.Request) {
+	target, _, ok := h.episodeRequest(w, r)
+	if !ok {
+		return
+	}
+	h.stream(w, r, episodePlan(target))
+}
+
+// episodeRequest reads the episode a magnet request is for and the
+// release query its results are shown with, writing the error itself when
+// ok is false.
+func (h *MagnetHandler) episodeRequest(w http.ResponseWriter, r *http.Request) (target episodeTarget, q releaseQuery, ok bool) {
+	var req episodeMagnetRequest
+	err := decodeRequest(r, &req)
+	if err == nil {
+		q, err = req.query(magnetPerPage)
+	}
+	if err != nil {
+		writeError(w, r, err.Error(), http.StatusBadRequest)
+		return target, q, false
+	}
+
+	target = episodeTarget{
+		Show:         req.Name,
+		Original:     req.Original,
+		Season:       req.Season,
+		Episode:      req.Episode,
+		EpisodeTitle: req.Title,
+		AirDate:      req.AirDate,
+	}
+	if tv, err := h.tmdb.GetTVTitles(req.ID); err != nil {
+		log.Printf("Magnet show lookup error: %v", err)
+	} else {
+		target.fromShow(tv)
+		if target.Daily && target.AirDate == "" {
+			target.AirDate = h.episodeAirDate(req.ID, req.Season, req.Episode)
+		}
+	}
+	if ct, ok := model.ParseContentType(req.Type); ok {
+		target.Anime = ct == model.ContentTypeAnime
+	}
+	return target, q, true
+}
+
+// episodeAirDate looks up an episode's air date when the caller did not
+// pass one, or returns "".
+func (h *MagnetHandler) episodeAirDate(id, season, episode int) string {
+	details, err := h.tmdb.GetSeasonDetails(id, season)
+	if err != nil {
+		log.Printf("Magnet season lookup error: %v", err)
+		return ""
+	}
+	for _, e := range details.Episodes {
+		if e.EpisodeNumber == episode {
+			return e.AirDate
+		}
+	}
+	return ""
+}
+
+// GetPersonMagnets looks up the best release for each of a person's most
+// relevant released movies, in filmography order.
+func (h *MagnetHandler) GetPersonMagnets(w http.ResponseWriter, r *http.Request) {
+	req := personMagnetRequest{Sort: tmdb.FilmographySortYear}
+	if err := decodeRequest(r, &req); err != nil {
+		writeError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+	id, limit := req.ID, req.Limit
+	if limit < 1 || limit > 20 {
+		limit = 10
+	}
+
+	person, err := h.tmdb.GetPersonDetails(id)
+	if err != nil {
+		log.Printf("TMDB person error: %v", err)
+		writeError(w, r, cleanTMDBError(err), http.StatusBadGateway)
+		return
+	}
+
+	// Only the released movies are candidates, so only they are looked up
+	// by the rating filter.
+	today := time.Now().Format("2006-01-02")
+	var movies []tmdb.FilmographyEntry
+	var refs []titleRef
+	for _, e := range person.Filmography(req.Sort) {
+		if e.MediaType == "movie" && e.Date != "" && e.Date <= today {
+			movies = append(movies, e)
+			refs = append(refs, titleRef{mediaType: e.MediaType, id: e.ID, adult: e.Adult})
+		}
+	}
+	f := h.ratings(r)
+	var queries []batchQuery
+	for i, ok := range f.allowed(refs) {
+		if !ok {
+			continue
+		}
+		queries = append(queries, batchQuery{ID: movies[i].ID, Title: movies[i].Title, Year: movies[i].Year()})
+		if len(queries) == limit {
+			break
+		}
+	}
+
+	log.Printf("Batch magnet search: person=%d movies=%d", id, len(queries))
+	h.writeBatchResults(w, r, BatchResponse{Results: h.searchBatch(r.Context(), f, queries)})
+}
+
+// GetCollectionMagnets searches every film of a TMDB collection concurrently
+// and, alongside, looks for collection/trilogy packs of the whole franchise.
+func (h *MagnetHandler) GetCollectionMagnets(w http.ResponseWriter, r *http.Request) {
+	var req collectionMagnetRequest
+	if err := decodeRequest(r, &req); err != nil {
+		writeError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+	id := req.ID
+
+	collection, err := h.tmdb.GetCollection(id)
+	if err != nil {
+		log.Printf("TMDB collection error: %v", err)
+		writeError(w, r, cleanTMDBError(err), http.StatusBadGateway)
+		return
+	}
+	collection.SortParts()
+	f := h.ratings(r)
+	// A pack bundles every film, including any the filter hides.
+	hidden := f.collection(collection)
+
+	today := time.Now().Format("2006-01-02")
+	var queries []batchQuery
+	for _, p := range collection.Parts {
+		if len(p.ReleaseDate) < 4 || p.ReleaseDate > today {
+			continue
+		}
+		queries = append(queries, batchQuery{ID: p.ID, Title: p.Title, Year: p.ReleaseDate[:4]})
+	}
+
+	ctx := r.Context()
+	packs := make(chan []jackett.Result, 1)
+	go func() {
+		if hidden {
+			packs <- nil
+			return
+		}
+		packs <- h.searchPacks(ctx, collection.BaseName())
+	}()
+
+	log.Printf("Batch magnet search: collection=%d movies=%d", id, len(queries))
+	resp := BatchResponse{Results: h.searchBatch(ctx, f, queries)}
+	resp.Packs = model.FromJackettAll(f.releases(<-packs))
+	h.writeBatchResults(w, r, resp)
+}
+
+// searchPacks queries "<name> collection" and "<name> trilogy" and keeps the
+// releases that look like multi-film packs.
+func (h *MagnetHandler) searchPacks(ctx context.Context, name string) []jackett.Result {
+	var packs []jackett.Result
+	for _, suffix := range []string{"collection", "trilogy"} {
+		query := slugify(name) + "-" + suffix
+		log.Printf("Magnet search (pack): %q", query)
+		found, err := h.fetcher.Search(ctx, query)
 		if err != nil {
-			log.Printf("Magnet search error (title query): %v", err)
-			results = results1
-		} else {
-			results = dedupe(results1, results2)
-			sort.Slice(results, func(i, j int) bool {
-				if results[i].Seeders != results[j].Seeders {
-					return results[i].Seeders > results[j].Seeders
-				}
-				return results[i].Peers > results[j].Peers
-			})
+			log.Printf("Magnet search error (pack query): %v", err)
+			continue
 		}
-	} else {
-		results = results1
+		var matched []jackett.Result
+		for _, r := range found {
+			if packPattern.MatchString(r.Title) {
+				matched = append(matched, r)
+			}
+		}
+		packs = dedupe(packs, matched)
 	}
+	sortBySeeders(packs)
+	return packs
+}
 
-	h.writeResults(w, results)
+func (h *MagnetHandler) writeBatchResults(w http.ResponseWriter, r *http.Request, results BatchResponse) {
+	render(w, r, h.template, "magnet_batch_results.html", results)
 }
 
-func (h *MagnetHandler) writeResults(w http.ResponseWriter, results []jackett.Result) {
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	if err := h.template.ExecuteTemplate(w, "magnet_results.html", results); err != nil {
-		log.Printf("Magnet template error: %v", err)
-		http.Error(w, "Failed to render results", http.StatusInternalServerError)
+// hitTTL is how long a magnet search's hits are kept, so paging and
+// filtering them does not search again.
+const hitTTL = 10 * time.Minute
+
+// hitCacheSize caps how many searches' hits are kept for paging.
+const hitCacheSize = 128
+
+// hitKey identifies a magnet search by its path and the parameters that
+// decide what it searches for, whichever format it is served in.
+func hitKey(r *http.Request) string {
+	params := searchParams(r.URL.Query())
+	params.Del("stream")
+	params.Del("format")
+	return strings.TrimSuffix(strings.TrimSuffix(r.URL.Path, "/"), "/stream") + "?" + params.Encode()
+}
+
+// cachedRun runs a plan unless the same search ran within hitTTL.
+func (h *MagnetHandler) cachedRun(r *http.Request, p *searchPlan) ([]MagnetHit, error) {
+	key := hitKey(r)
+	if hits, ok := h.hits.Get(key); ok {
+		return hits, nil
+	}
+	hits, err := h.run(r.Context(), p)
+	if err != nil {
+		return nil, err
+	}
+	h.hits.Put(key, hits)
+	return hits, nil
+}
+
+// MagnetPage is one page of magnet results with what the filter bar and
+// the pager need. Found counts the hits before the filters, Total after.
+type MagnetPage struct {
+	Hits       []MagnetHit           `json:"results"`
+	Found      int                   `json:"found"`
+	Total      int                   `json:"total_results"`
+	Page       int                   `json:"page"`
+	PerPage    int                   `json:"per_page"`
+	TotalPages int                   `json:"total_pages"`
+	Trackers   []string              `json:"trackers"`
+	Warning    *tmdb.ReleaseSchedule `json:"release_warning,omitempty"`
+	// Selected holds the raw filter and sort parameters, to keep the
+	// filter bar as the user left it.
+	Selected    url.Values `json:"-"`
+	Resolutions []string   `json:"-"`
+	Codecs      []string   `json:"-"`
+	// BaseURL is the search without any filter, sort or page.
+	BaseURL string `json:"-"`
+}
+
+// pageURL links to another page with the same filters and sort.
+func (p MagnetPage) pageURL(page int) string {
+	v := url.Values{}
+	for key, values := range p.Selected {
+		v[key] = values
 	}
+	v.Set("page", strconv.Itoa(page))
+	if strings.HasSuffix(p.BaseURL, "?") {
+		return p.BaseURL + v.Encode()
+	}
+	return p.BaseURL + "&" + v.Encode()
+}
+
+func (p MagnetPage) PrevURL() string { return p.pageURL(p.Page - 1) }
+func (p MagnetPage) NextURL() string { return p.pageURL(p.Page + 1) }
+
+// writeResults renders the page of hits q, read from the request's
+// filters, sort and page parameters, asks for.
+func (h *MagnetHandler) writeResults(w http.ResponseWriter, r *http.Request, hits []MagnetHit, q releaseQuery, warning *tmdb.ReleaseSchedule) {
+	f := h.ratings(r)
+	allowed := make([]MagnetHit, 0, len(hits))
+	trackers := make(map[string]bool)
+	for _, hit := range hits {
+		if f.allowsRelease(hit.Title) {
+			allowed = append(allowed, hit)
+			trackers[strings.ToLower(hit.Tracker)] = true
+		}
+	}
+
+	page, total := apply(allowed, func(hit *MagnetHit) *jackett.Result { return &hit.Result }, q)
+	data := MagnetPage{
+		Hits:        page,
+		Found:       len(allowed),
+		Total:       total,
+		Page:        q.Page,
+		PerPage:     q.PerPage,
+		TotalPages:  q.totalPages(total),
+		Trackers:    []string{},
+		Selected:    viewParams(r.URL.Query()),
+		Resolutions: releaseResolutions,
+		Codecs:      releaseCodecs,
+		BaseURL:     hitKey(r),
+		Warning:     warning,
+	}
+	data.Selected.Del("page")
+	for t := range trackers {
+		if t != "" {
+			data.Trackers = append(data.Trackers, t)
+		}
+	}
+	sort.Strings(data.Trackers)
+
+	render(w, r, h.template, "magnet_results.html", data)
 }
@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestEpisodeMagnetsValidation(t *testing.T) {
+	r := chi.NewRouter()
+	r.Get("/magnet/episode/{id}/s{season}/e{episode}", (&MagnetHandler{}).GetEpisodeMagnets)
+
+	tests := []string{
+		"/magnet/episode/1/sx/e1?name=Show",
+		"/magnet/episode/1/s1/e1?type=tv",
+		"/magnet/episode/1/s1/e1?name=Show&per_page=500",
+	}
+	for _, path := range tests {
+		for _, stream := range []string{"", "&stream=1"} {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", path+stream, nil))
+			if w.Code != 400 {
+				t.Errorf("GET %s%s = %d, want 400", path, stream, w.Code)
+			}
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"html/template"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// wantsJSON reports whether a page or magnet request asked for JSON:
+// ?format=json, or an Accept header preferring application/json to HTML.
+// ?format=html forces HTML whatever the header says.
+func wantsJSON(r *http.Request) bool {
+	switch r.URL.Query().Get("format") {
+	case "json":
+		return true
+	case "html":
+		return false
+	}
+
+	jsonQ, htmlQ := 0.0, 0.0
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if s, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(s, 64); err != nil {
+				continue
+			}
+		}
+		switch mediaType {
+		case "application/json":
+			jsonQ = max(jsonQ, q)
+		case "text/html":
+			htmlQ = max(htmlQ, q)
+		}
+	}
+	return jsonQ > 0 && jsonQ > htmlQ
+}
+
+// render writes a view model as JSON when the request asked for it, and
+// otherwise executes the named template with it. Template-only keys of
+// map view models are left out of the JSON.
+func render(w http.ResponseWriter, r *http.Request, tmpl *template.Template, name string, data interface{}) {
+	w.Header().Add("Vary", "Accept")
+	if wantsJSON(r) {
+		if m, ok := data.(map[string]interface{}); ok {
+			delete(m, "IsHome")
+		}
+		writeJSON(w, data)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := tmpl.ExecuteTemplate(w, name, data); err != nil {
+		log.Printf("Template error (%s): %v", name, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// writeError writes an error as {"error": msg} to requests that asked for
+// JSON and as plain text to the rest.
+func writeError(w http.ResponseWriter, r *http.Request, msg string, status int) {
+	if wantsJSON(r) {
+		writeJSONError(w, msg, status)
+		return
+	}
+	http.Error(w, msg, status)
+}
+
+// PreferJSON makes JSON the default for routes that also speak HTML, so
+// API clients get JSON without an Accept header. ?format=html still wins.
+func PreferJSON(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("format") == "" {
+			q.Set("format", "json")
+			r.URL.RawQuery = q.Encode()
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -2,12 +2,21 @@ package handler
 
 import (
 	"fmt"
+	"log"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
-	"github.com/go-chi/chi/v5"
+	"github.com/unedtamps/orbit/internal/lookup"
+	"github.com/unedtamps/orbit/internal/tmdb"
 )
 
+func (h *Handler) ratings(r *http.Request) *ratingFilter {
+	return newRatingFilter(h.tmdb, h.prefs, h.prefs.resolve(r))
+}
+
 func (h *Handler) Index(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	if err := h.template.ExecuteTemplate(w, "index.html", nil); err != nil {
@@ -16,115 +25,363 @@ func (h *Handler) Index(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) SearchPage(w http.ResponseWriter, r *http.Request) {
-	query := r.URL.Query().Get("q")
+	var req pageSearchRequest
+	if err := decodeRequest(r, &req); err != nil {
+		writeError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+	query := req.Query
 	if query == "" {
 		http.Redirect(w, r, "/", http.StatusFound)
 		return
 	}
+	// Pasted IMDb IDs and links jump straight to the title.
+	if lookup.IsLink(query) {
+		target := "/lookup?url=" + url.QueryEscape(query)
+		if r.Header.Get("HX-Request") == "true" {
+			w.Header().Set("HX-Redirect", target)
+			return
+		}
+		http.Redirect(w, r, target, http.StatusFound)
+		return
+	}
 
 	data := map[string]interface{}{
 		"Query":  query,
 		"IsHome": false,
 	}
 
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-
 	// If this is an HTMX request, return just the results fragment
 	if r.Header.Get("HX-Request") == "true" {
-		if err := h.template.ExecuteTemplate(w, "search_results_partial.html", data); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-		}
+		render(w, r, h.template, "search_results_partial.html", data)
 		return
 	}
 
 	// Otherwise, return the full page
-	if err := h.template.ExecuteTemplate(w, "search.html", data); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-	}
+	render(w, r, h.template, "search.html", data)
 }
 
 func (h *Handler) MovieDetailPage(w http.ResponseWriter, r *http.Request) {
-	id, err := strconv.Atoi(chi.URLParam(r, "id"))
-	if err != nil {
-		http.Error(w, "Invalid movie ID", http.StatusBadRequest)
+	var req pageIDRequest
+	if err := decodeRequest(r, &req); err != nil {
+		writeError(w, r, err.Error(), http.StatusBadRequest)
 		return
 	}
+	id := req.ID
 
-	movie, err := h.tmdb.GetMovieDetails(id)
+	movie, prov, err := h.meta.GetMovieDetails(id)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to fetch movie: %v", err), http.StatusInternalServerError)
+		writeError(w, r, fmt.Sprintf("Failed to fetch movie: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if !h.ratings(r).movie(movie) {
+		writeRatingBlocked(w, r)
 		return
 	}
 
+	up := h.prefs.resolve(r)
+	imdbID, imdbRating := movieIMDb(h.imdb, movie)
 	data := map[string]interface{}{
-		"Movie":  movie,
-		"IsHome": false,
+		"Movie":        movie,
+		"IMDbID":       imdbID,
+		"IMDb":         imdbRating,
+		"Availability": availability(movie.WatchProviders, up),
+		"Release":      movie.Schedule(up.Region, time.Now()),
+		"Provenance":   prov,
+		"IsHome":       false,
 	}
 
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	if err := h.template.ExecuteTemplate(w, "movie_detail.html", data); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-	}
+	render(w, r, h.template, "movie_detail.html", data)
 }
 
 func (h *Handler) TVDetailPage(w http.ResponseWriter, r *http.Request) {
-	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	var req pageIDRequest
+	if err := decodeRequest(r, &req); err != nil {
+		writeError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+	id := req.ID
+
+	tv, prov, err := h.meta.GetTVDetails(id)
+	if err != nil {
+		writeError(w, r, fmt.Sprintf("Failed to fetch TV show: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if !h.ratings(r).tv(tv) {
+		writeRatingBlocked(w, r)
+		return
+	}
+
+	imdbID, imdbRating := tvIMDb(h.imdb, tv)
+	data := map[string]interface{}{
+		"TV":           tv,
+		"TVID":         id,
+		"IMDbID":       imdbID,
+		"IMDb":         imdbRating,
+		"Availability": availability(tv.WatchProviders, h.prefs.resolve(r)),
+		"Provenance":   prov,
+		"IsHome":       false,
+	}
+
+	render(w, r, h.template, "tv_detail.html", data)
+}
+
+func (h *Handler) SeasonPage(w http.ResponseWriter, r *http.Request) {
+	var req pageSeasonRequest
+	if err := decodeRequest(r, &req); err != nil {
+		writeError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+	tvID, seasonNum := req.ID, req.Season
+
+	season, prov, err := h.meta.GetSeasonDetails(tvID, seasonNum)
 	if err != nil {
-		http.Error(w, "Invalid TV ID", http.StatusBadRequest)
+		writeError(w, r, fmt.Sprintf("Failed to fetch season: %v", err), http.StatusInternalServerError)
 		return
 	}
 
-	tv, err := h.tmdb.GetTVDetails(id)
+	tv, _, err := h.meta.GetTVDetails(tvID)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to fetch TV show: %v", err), http.StatusInternalServerError)
+		writeError(w, r, fmt.Sprintf("Failed to fetch TV show: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if !h.ratings(r).tv(tv) {
+		writeRatingBlocked(w, r)
 		return
 	}
 
+	showIMDbID, _ := tvIMDb(h.imdb, tv)
 	data := map[string]interface{}{
-		"TV":     tv,
-		"TVID":   id,
-		"IsHome": false,
+		"TVName":         tv.Name,
+		"TVOriginalName": tv.OriginalName,
+		"TVID":           tvID,
+		"Season":         season,
+		"IMDbEpisodes":   h.imdb.Season(showIMDbID, seasonNum),
+		"Provenance":     prov,
+		"IsHome":         false,
 	}
 
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	if err := h.template.ExecuteTemplate(w, "tv_detail.html", data); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	render(w, r, h.template, "season_detail.html", data)
+}
+
+func (h *Handler) PersonPage(w http.ResponseWriter, r *http.Request) {
+	req := pagePersonRequest{personRequest: personRequest{Sort: tmdb.FilmographySortYear}}
+	if err := decodeRequest(r, &req); err != nil {
+		writeError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+	id, sortBy := req.ID, req.Sort
+
+	person, err := h.tmdb.GetPersonDetails(id)
+	if err != nil {
+		writeError(w, r, fmt.Sprintf("Failed to fetch person: %v", err), http.StatusInternalServerError)
+		return
 	}
+	h.ratings(r).person(person)
+
+	data := map[string]interface{}{
+		"Person":      person,
+		"Filmography": person.Filmography(sortBy),
+		"Sort":        sortBy,
+		"IsHome":      false,
+	}
+
+	render(w, r, h.template, "person_detail.html", data)
 }
 
-func (h *Handler) SeasonPage(w http.ResponseWriter, r *http.Request) {
-	tvID, err := strconv.Atoi(chi.URLParam(r, "id"))
+func (h *Handler) CollectionPage(w http.ResponseWriter, r *http.Request) {
+	var req pageIDRequest
+	if err := decodeRequest(r, &req); err != nil {
+		writeError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+	id := req.ID
+
+	collection, err := h.tmdb.GetCollection(id)
 	if err != nil {
-		http.Error(w, "Invalid TV ID", http.StatusBadRequest)
+		writeError(w, r, fmt.Sprintf("Failed to fetch collection: %v", err), http.StatusInternalServerError)
+		return
+	}
+	collection.SortParts()
+	h.ratings(r).collection(collection)
+
+	data := map[string]interface{}{
+		"Collection": collection,
+		"IsHome":     false,
+	}
+
+	render(w, r, h.template, "collection_detail.html", data)
+}
+
+// discoverMaxPage is the last page TMDB's discover endpoint will serve.
+const discoverMaxPage = 500
+
+func (h *Handler) GenrePage(w http.ResponseWriter, r *http.Request) {
+	req := pageGenreRequest{genreTitlesRequest: genreTitlesRequest{Type: "movie"}}
+	if err := decodeRequest(r, &req); err != nil {
+		writeError(w, r, err.Error(), http.StatusBadRequest)
 		return
 	}
-	seasonNum, err := strconv.Atoi(chi.URLParam(r, "season"))
+	id, mediaType, page := req.ID, req.Type, min(max(req.Page, 1), discoverMaxPage)
+
+	genres, err := h.tmdb.GetGenres(mediaType, "en-US")
 	if err != nil {
-		http.Error(w, "Invalid season number", http.StatusBadRequest)
+		writeError(w, r, fmt.Sprintf("Failed to fetch genres: %v", err), http.StatusInternalServerError)
+		return
+	}
+	var genre *tmdb.Genre
+	for i := range genres {
+		if genres[i].ID == id {
+			genre = &genres[i]
+			break
+		}
+	}
+	if genre == nil {
+		writeError(w, r, "Genre not found", http.StatusNotFound)
 		return
 	}
 
-	season, err := h.tmdb.GetSeasonDetails(tvID, seasonNum)
+	// Movie and TV genres only partly share IDs, so the media type tabs
+	// fall back to the other list's first genre when this one has no twin.
+	otherType := "tv"
+	if mediaType == "tv" {
+		otherType = "movie"
+	}
+	otherID := 0
+	if others, err := h.tmdb.GetGenres(otherType, "en-US"); err == nil && len(others) > 0 {
+		otherID = others[0].ID
+		for _, g := range others {
+			if g.ID == id {
+				otherID = id
+				break
+			}
+		}
+	}
+
+	results, err := h.tmdb.Discover(mediaType, id, page)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to fetch season: %v", err), http.StatusInternalServerError)
+		writeError(w, r, fmt.Sprintf("Failed to fetch genre titles: %v", err), http.StatusInternalServerError)
+		return
+	}
+	totalPages := min(results.TotalPages, discoverMaxPage)
+
+	data := map[string]interface{}{
+		"Genre":      genre,
+		"Genres":     genres,
+		"MediaType":  mediaType,
+		"OtherID":    otherID,
+		"Results":    h.ratings(r).filter(results.Results, mediaType),
+		"Page":       page,
+		"TotalPages": totalPages,
+		"PrevPage":   page - 1,
+		"NextPage":   page + 1,
+		"IsHome":     false,
+	}
+
+	render(w, r, h.template, "genre.html", data)
+}
+
+func (h *Handler) CalendarPage(w http.ResponseWriter, r *http.Request) {
+	var req pageCalendarRequest
+	if err := decodeRequest(r, &req); err != nil {
+		writeError(w, r, err.Error(), http.StatusBadRequest)
 		return
 	}
+	region := h.prefs.resolve(r).Region
+	days := calendarDays(req.Days)
 
-	tv, err := h.tmdb.GetTVDetails(tvID)
+	calendar, err := buildCalendar(h.tmdb, region, days)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to fetch TV show: %v", err), http.StatusInternalServerError)
+		writeError(w, r, fmt.Sprintf("Failed to fetch calendar: %v", err), http.StatusInternalServerError)
 		return
 	}
 
 	data := map[string]interface{}{
-		"TVName":  tv.Name,
-		"TVID":    tvID,
-		"Season":  season,
-		"IsHome":  false,
+		"Calendar": h.ratings(r).calendar(calendar),
+		"Region":   region,
+		"Days":     days,
+		"IsHome":   false,
 	}
 
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	if err := h.template.ExecuteTemplate(w, "season_detail.html", data); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	render(w, r, h.template, "calendar.html", data)
+}
+
+func (h *Handler) SettingsPage(w http.ResponseWriter, r *http.Request) {
+	up := h.prefs.resolve(r)
+
+	providers, err := h.tmdb.GetWatchProviderList(up.Region)
+	if err != nil {
+		log.Printf("TMDB watch provider list error: %v", err)
+	}
+	regions, err := h.tmdb.GetWatchRegions()
+	if err != nil {
+		log.Printf("TMDB watch regions error: %v", err)
+	}
+
+	data := map[string]interface{}{
+		"Region":              up.Region,
+		"Regions":             regions,
+		"Providers":           providers,
+		"Selected":            up.Providers,
+		"Country":             h.prefs.CertificationCountry,
+		"MovieRatings":        h.ratingOptions("movie", h.prefs.MaxMovieRating),
+		"TVRatings":           h.ratingOptions("tv", h.prefs.MaxTVRating),
+		"MaxMovieRating":      up.MaxMovieRating,
+		"MaxTVRating":         up.MaxTVRating,
+		"InstanceMovieRating": h.prefs.MaxMovieRating,
+		"InstanceTVRating":    h.prefs.MaxTVRating,
+		"Saved":               r.URL.Query().Get("saved") == "1",
+		"IsHome":              false,
+	}
+
+	render(w, r, h.template, "settings.html", data)
+}
+
+// ratingOptions lists the ratings a browser may pick as its limit: every
+// rated certification of the country, up to the instance limit if set.
+func (h *Handler) ratingOptions(mediaType, instanceMax string) []tmdb.Certification {
+	lists, err := h.tmdb.GetCertifications(mediaType)
+	if err != nil {
+		log.Printf("TMDB %s certifications error: %v", mediaType, err)
+		return nil
+	}
+	max := h.tmdb.CertificationRank(mediaType, h.prefs.CertificationCountry, instanceMax)
+	var options []tmdb.Certification
+	for _, c := range lists[h.prefs.CertificationCountry] {
+		if c.Order < 1 || (max >= 0 && c.Order > max) {
+			continue
+		}
+		options = append(options, c)
+	}
+	return options
+}
+
+// SaveSettings stores the watch region, subscribed providers and rating
+// limits in cookies.
+func (h *Handler) SaveSettings(w http.ResponseWriter, r *http.Request) {
+	if !sameOrigin(r) {
+		http.Error(w, "Cross-origin request refused", http.StatusForbidden)
+		return
 	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form", http.StatusBadRequest)
+		return
+	}
+
+	region := parseRegion(r.PostForm.Get("region"))
+	if region == "" {
+		region = h.prefs.WatchRegion
+	}
+
+	ids := parseProviderIDs(r.PostForm["provider"])
+	values := make([]string, len(ids))
+	for i, id := range ids {
+		values[i] = strconv.Itoa(id)
+	}
+
+	setPrefsCookie(w, regionCookie, region)
+	setPrefsCookie(w, providersCookie, strings.Join(values, "."))
+	setPrefsCookie(w, movieRatingCookie, parseRating(r.PostForm.Get("max_movie_rating")))
+	setPrefsCookie(w, tvRatingCookie, parseRating(r.PostForm.Get("max_tv_rating")))
+	http.Redirect(w, r, "/settings?saved=1", http.StatusSeeOther)
 }
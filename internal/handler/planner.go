@@ -0,0 +1,406 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/unedtamps/orbit/internal/fansub"
+	"github.com/unedtamps/orbit/internal/fetcher"
+	"github.com/unedtamps/orbit/internal/model"
+	"github.com/unedtamps/orbit/internal/tmdb"
+
+	jackett "github.com/webtor-io/go-jackett"
+)
+
+const (
+	// planBudget caps how many searches one magnet lookup may run.
+	planBudget = 8
+	// planEnough relevant hits end a lookup before its budget is spent.
+	planEnough = 15
+	// planConcurrency is how many strategies run at once. Strategies run
+	// in rank-ordered waves of this size so early stops save searches.
+	planConcurrency = 3
+	// sceneTitleLimit caps how many alternative titles get a strategy.
+	sceneTitleLimit = 3
+)
+
+// Strategy names, reported on every hit.
+const (
+	StrategyID           = "ID"
+	StrategyTitle        = "title"
+	StrategyOriginal     = "original title"
+	StrategyAlternative  = "alternative title"
+	StrategyScene        = "scene name"
+	StrategyNoYear       = "without year"
+	StrategyCrossNumber  = "1x02 numbering"
+	StrategyAbsolute     = "absolute numbering"
+	StrategyEpisodeTitle = "episode title"
+	StrategyFansub       = "fansub numbering"
+	StrategyAirDate      = "air date"
+	StrategySeason       = "season search"
+)
+
+// strategy is one way of asking for a title: a text query, or an ID
+// lookup when id is set. Anime strategies search the anime categories.
+type strategy struct {
+	name  string
+	query string
+	id    *fetcher.IDQuery
+	anime bool
+}
+
+// MagnetHit is a release along with the strategy that first found it
+// and, for episodes, how it covers the episode when that needs saying.
+type MagnetHit struct {
+	jackett.Result
+	Strategy string `json:"strategy"`
+	Match    string `json:"match,omitempty"`
+}
+
+// MagnetRelease is a MagnetHit as the JSON APIs send it.
+type MagnetRelease struct {
+	model.Release
+	Strategy string `json:"strategy"`
+	Match    string `json:"match,omitempty"`
+}
+
+func (h MagnetHit) MarshalJSON() ([]byte, error) {
+	return json.Marshal(MagnetRelease{Release: model.FromJackett(h.Result), Strategy: h.Strategy, Match: h.Match})
+}
+
+// searchPlan is a ranked list of strategies and a test for whether a hit
+// is about the right title, which may also label the hit.
+type searchPlan struct {
+	strategies []strategy
+	match      func(jackett.Result) (label string, ok bool)
+	// strict drops text-search hits that do not match. ID lookups ask
+	// for the exact title and are kept whatever their names say.
+	strict bool
+	// budget overrides planBudget when set.
+	budget int
+}
+
+// hit labels a release a strategy returned. relevant hits count towards
+// planEnough; keep is false when a strict plan drops the release.
+func (p *searchPlan) hit(s strategy, r jackett.Result) (hit MagnetHit, relevant, keep bool) {
+	label, ok := p.match(r)
+	relevant = s.id != nil || ok
+	if p.strict && !relevant {
+		return MagnetHit{}, false, false
+	}
+	return MagnetHit{Result: r, Strategy: s.name, Match: label}, relevant, true
+}
+
+// add appends a text strategy unless an earlier one already asks the
+// same thing.
+func (p *searchPlan) add(name, query string) {
+	p.addText(strategy{name: name, query: query})
+}
+
+// addAnime appends a text strategy limited to the anime categories.
+func (p *searchPlan) addAnime(name, query string) {
+	p.addText(strategy{name: name, query: query, anime: true})
+}
+
+func (p *searchPlan) addText(s strategy) {
+	if s.query == "" {
+		return
+	}
+	for _, existing := range p.strategies {
+		if existing.id == nil && existing.query == s.query {
+			return
+		}
+	}
+	p.strategies = append(p.strategies, s)
+}
+
+func (p *searchPlan) addID(q fetcher.IDQuery) {
+	if q.IMDbID == "" && q.TVDBID == 0 {
+		return
+	}
+	p.strategies = append(p.strategies, strategy{name: StrategyID, id: &q})
+}
+
+// movieTarget is what the planner knows about a film.
+type movieTarget struct {
+	Title    string
+	Original string
+	Year     string
+	IMDbID   string
+	AltNames []string
+}
+
+func moviePlan(t movieTarget) *searchPlan {
+	p := &searchPlan{}
+	p.addID(fetcher.IDQuery{IMDbID: t.IMDbID})
+
+	variants := titleVariants(t.Title)
+	for _, v := range variants {
+		p.add(StrategyTitle, withYear(v, t.Year))
+	}
+	for _, v := range titleVariants(t.Original) {
+		p.add(StrategyOriginal, withYear(v, t.Year))
+	}
+	p.add(StrategyScene, sceneName(withYear(slugify(t.Title), t.Year)))
+	for _, v := range titleVariants(t.AltNames...) {
+		p.add(StrategyAlternative, withYear(v, t.Year))
+	}
+	for _, v := range variants {
+		p.add(StrategyNoYear, v)
+	}
+
+	names := titleVariants(append([]string{t.Title, t.Original}, t.AltNames...)...)
+	p.match = func(r jackett.Result) (string, bool) { return "", mentions(r.Title, names) }
+	return p
+}
+
+// episodeTarget is what the planner knows about an episode. Absolute is
+// the episode's position across all regular seasons, 0 when unknown.
+// Anime episodes are searched by fansub numbering first, and daily
+// shows by AirDate (YYYY-MM-DD).
+type episodeTarget struct {
+	Show         string
+	Original     string
+	Season       int
+	Episode      int
+	Absolute     int
+	EpisodeTitle string
+	TVDBID       int
+	IMDbID       string
+	AltNames     []string
+	Anime        bool
+	Daily        bool
+	AirDate      string
+}
+
+// fromShow fills in what the show's details say about the episode.
+func (t *episodeTarget) fromShow(tv *tmdb.TVDetails) {
+	if t.Show == "" {
+		t.Show = tv.Name
+	}
+	if t.Original == "" {
+		t.Original = tv.OriginalName
+	}
+	t.Anime = tv.IsAnime()
+	t.Daily = tv.IsDaily()
+	t.Absolute = tv.AbsoluteNumber(t.Season, t.Episode)
+	t.AltNames = tv.SceneTitles(sceneTitleLimit)
+	if tv.ExternalIDs != nil {
+		t.TVDBID = tv.ExternalIDs.TVDBID
+		t.IMDbID = tv.ExternalIDs.IMDbID
+	}
+}
+
+func episodePlan(t episodeTarget) *searchPlan {
+	// Season searches return every episode of the season; only releases
+	// covering this one are kept.
+	p := &searchPlan{strict: true}
+	p.addID(fetcher.IDQuery{TVDBID: t.TVDBID, IMDbID: t.IMDbID, Season: t.Season, Episode: t.Episode})
+	if t.Anime {
+		addFansub(p, t)
+	}
+	variants := titleVariants(t.Show)
+	if t.Daily && t.AirDate != "" {
+		date := strings.ReplaceAll(t.AirDate, "-", ".")
+		for _, v := range variants {
+			p.add(StrategyAirDate, sceneName(v)+"."+date)
+		}
+	}
+
+	code := fmt.Sprintf("s%02de%02d", t.Season, t.Episode)
+	for _, v := range variants {
+		p.add(StrategyTitle, v+"-"+code)
+	}
+	// Specials are numbered differently from tracker to tracker, so
+	// their titles are the better bet.
+	if t.Season == 0 && t.EpisodeTitle != "" {
+		p.add(StrategyEpisodeTitle, slugify(t.Show)+"-"+slugify(t.EpisodeTitle))
+	}
+	for _, v := range titleVariants(t.Original) {
+		p.add(StrategyOriginal, v+"-"+code)
+	}
+	p.add(StrategyScene, sceneName(slugify(t.Show)+"-"+code))
+	for _, v := range titleVariants(t.AltNames...) {
+		p.add(StrategyAlternative, v+"-"+code)
+	}
+	for _, v := range variants {
+		p.add(StrategyCrossNumber, fmt.Sprintf("%s-%dx%02d", v, t.Season, t.Episode))
+	}
+	if t.Absolute > 0 {
+		for _, v := range variants {
+			p.add(StrategyAbsolute, fmt.Sprintf("%s-%02d", v, t.Absolute))
+		}
+	}
+	if t.EpisodeTitle != "" {
+		p.add(StrategyEpisodeTitle, slugify(t.Show)+"-"+slugify(t.EpisodeTitle))
+	}
+	// Multi-episode files and season packs rarely turn up when searching
+	// for a single episode code.
+	if t.Season > 0 && !t.Daily {
+		p.add(StrategySeason, fmt.Sprintf("%s-s%02d", slugify(t.Show), t.Season))
+	}
+
+	names := titleVariants(append([]string{t.Show, t.Original}, t.AltNames...)...)
+	p.match = func(r jackett.Result) (string, bool) {
+		if !mentions(r.Title, names) {
+			return "", false
+		}
+		return t.match(r.Title)
+	}
+	return p
+}
+
+// addFansub adds the fansub-style queries for an anime episode, such as
+// "Title - 13 [1080p]": by absolute number for every known name, then
+// without the resolution tag, then numbered within the season for groups
+// that restart at each season.
+func addFansub(p *searchPlan, t episodeTarget) {
+	number := t.Absolute
+	if number == 0 {
+		number = t.Episode
+	}
+	names := titleVariants(append([]string{t.Show, t.Original}, t.AltNames...)...)
+	for _, n := range names {
+		p.addAnime(StrategyFansub, fansub.Query(fansubTitle(n), number, fansub.Resolution))
+	}
+	if len(names) > 0 {
+		p.addAnime(StrategyFansub, fansub.Query(fansubTitle(names[0]), number, ""))
+		if t.Season > 1 {
+			p.addAnime(StrategyFansub, fansub.Query(fmt.Sprintf("%s S%d", fansubTitle(names[0]), t.Season), t.Episode, ""))
+		}
+	}
+}
+
+// fansubTitle turns a slug back into the spaced words fansub names use.
+func fansubTitle(slug string) string {
+	return strings.ReplaceAll(slug, "-", " ")
+}
+
+// fansubCovers reports whether a fansub-style release includes the
+// episode, numbered absolutely or within its season.
+func fansubCovers(r fansub.Release, t episodeTarget) bool {
+	if t.Absolute > 0 && r.Season == 0 && r.Covers(t.Absolute) {
+		return true
+	}
+	if r.Season == t.Season || (r.Season == 0 && t.Season == 1) {
+		return r.Covers(t.Episode)
+	}
+	return false
+}
+
+// sceneName turns a slug into the dot-separated style of scene releases.
+func sceneName(slug string) string {
+	return strings.ReplaceAll(slug, "-", ".")
+}
+
+// mentions reports whether a release name contains one of the title slugs.
+func mentions(release string, names []string) bool {
+	latin := slugify(releaseWords(release))
+	native := nativeSlug(releaseWords(release))
+	for _, n := range names {
+		if strings.Contains(latin, n) || strings.Contains(native, n) {
+			return true
+		}
+	}
+	return false
+}
+
+// run executes the plan's strategies in rank order and in waves of
+// planConcurrency, stopping once planEnough relevant hits are in or the
+// budget is spent. Hits are credited to the best-ranked strategy that
+// returned them and sorted by seeders. It only fails when every search
+// it ran did.
+func (h *MagnetHandler) run(ctx context.Context, p *searchPlan) ([]MagnetHit, error) {
+	budget := planBudget
+	if p.budget > 0 {
+		budget = p.budget
+	}
+	strategies := p.strategies
+	if len(strategies) > budget {
+		strategies = strategies[:budget]
+	}
+
+	var hits []MagnetHit
+	seen := make(map[string]bool)
+	relevant, ran, failed := 0, 0, 0
+	var lastErr error
+
+	for start := 0; start < len(strategies) && relevant < planEnough; start += planConcurrency {
+		end := start + planConcurrency
+		if end > len(strategies) {
+			end = len(strategies)
+		}
+		wave := strategies[start:end]
+		found := make([][]jackett.Result, len(wave))
+		errs := make([]error, len(wave))
+
+		var wg sync.WaitGroup
+		for i, s := range wave {
+			wg.Add(1)
+			go func(i int, s strategy) {
+				defer wg.Done()
+				found[i], errs[i] = h.search(ctx, s)
+			}(i, s)
+		}
+		wg.Wait()
+
+		for i, s := range wave {
+			ran++
+			if errs[i] != nil {
+				failed++
+				lastErr = errs[i]
+				log.Printf("Magnet search error (%s %q): %v", s.name, s.query, errs[i])
+				continue
+			}
+			for _, r := range found[i] {
+				key := dedupeKey(r)
+				if seen[key] {
+					continue
+				}
+				hit, ok, keep := p.hit(s, r)
+				if !keep {
+					continue
+				}
+				seen[key] = true
+				hits = append(hits, hit)
+				if ok {
+					relevant++
+				}
+			}
+		}
+	}
+
+	log.Printf("Magnet plan: ran %d of %d strategies, %d hits, %d relevant", ran, len(p.strategies), len(hits), relevant)
+	if ran > 0 && failed == ran {
+		return nil, lastErr
+	}
+	sortHits(hits)
+	return hits, nil
+}
+
+func (h *MagnetHandler) search(ctx context.Context, s strategy) ([]jackett.Result, error) {
+	if s.id != nil {
+		log.Printf("Magnet search (%s): %+v", s.name, *s.id)
+		return h.fetcher.SearchByID(ctx, *s.id)
+	}
+	log.Printf("Magnet search (%s): %q", s.name, s.query)
+	if s.anime {
+		return h.fetcher.FetchAnime(ctx, s.query)
+	}
+	return h.fetcher.Search(ctx, s.query)
+}
+
+// sortHits orders hits by seeders, then peers, like sortBySeeders.
+func sortHits(hits []MagnetHit) {
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].Seeders != hits[j].Seeders {
+			return hits[i].Seeders > hits[j].Seeders
+		}
+		return hits[i].Peers > hits[j].Peers
+	})
+}
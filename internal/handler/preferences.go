@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/unedtamps/orbit/internal/tmdb"
+)
+
+const (
+	regionCookie      = "orbit_region"
+	providersCookie   = "orbit_providers"
+	movieRatingCookie = "orbit_max_movie_rating"
+	tvRatingCookie    = "orbit_max_tv_rating"
+	prefsMaxAge       = 365 * 24 * time.Hour
+)
+
+var (
+	regionPattern = regexp.MustCompile(`^[A-Z]{2}$`)
+	ratingPattern = regexp.MustCompile(`^[A-Za-z0-9+\-]{1,10}$`)
+)
+
+// Preferences holds the instance-wide defaults. Each browser can override
+// them through cookies set on the settings page, except that a browser can
+// only tighten the instance's rating limits, never relax them.
+type Preferences struct {
+	WatchRegion          string
+	CertificationCountry string
+	MaxMovieRating       string
+	MaxTVRating          string
+}
+
+// UserPrefs are the preferences resolved for a single request.
+type UserPrefs struct {
+	Region    string
+	Providers map[int]bool
+	// MaxMovieRating and MaxTVRating are the browser's own limits; the
+	// instance limits are applied on top by newRatingFilter.
+	MaxMovieRating string
+	MaxTVRating    string
+}
+
+// resolve applies the request's cookies, and a ?region= override, on top
+// of the instance defaults.
+func (p Preferences) resolve(r *http.Request) UserPrefs {
+	up := UserPrefs{Region: p.WatchRegion, Providers: make(map[int]bool)}
+
+	if c, err := r.Cookie(regionCookie); err == nil {
+		if region := parseRegion(c.Value); region != "" {
+			up.Region = region
+		}
+	}
+	if region := parseRegion(r.URL.Query().Get("region")); region != "" {
+		up.Region = region
+	}
+	if c, err := r.Cookie(providersCookie); err == nil {
+		for _, id := range parseProviderIDs(strings.Split(c.Value, ".")) {
+			up.Providers[id] = true
+		}
+	}
+	if c, err := r.Cookie(movieRatingCookie); err == nil {
+		up.MaxMovieRating = parseRating(c.Value)
+	}
+	if c, err := r.Cookie(tvRatingCookie); err == nil {
+		up.MaxTVRating = parseRating(c.Value)
+	}
+	return up
+}
+
+func parseRegion(s string) string {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if !regionPattern.MatchString(s) {
+		return ""
+	}
+	return s
+}
+
+func parseRating(s string) string {
+	s = strings.TrimSpace(s)
+	if !ratingPattern.MatchString(s) {
+		return ""
+	}
+	return s
+}
+
+func parseProviderIDs(values []string) []int {
+	var ids []int
+	for _, v := range values {
+		if id, err := strconv.Atoi(v); err == nil && id > 0 {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func setPrefsCookie(w http.ResponseWriter, name, value string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   int(prefsMaxAge.Seconds()),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// sameOrigin reports whether a state-changing request came from one of
+// Orbit's own pages, judged by its Origin header or, failing that, its
+// Referer. Requests with neither come from non-browser clients, which
+// carry no ambient cookies to abuse, and are let through.
+func sameOrigin(r *http.Request) bool {
+	source := r.Header.Get("Origin")
+	if source == "" {
+		source = r.Header.Get("Referer")
+	}
+	if source == "" {
+		return true
+	}
+	u, err := url.Parse(source)
+	if err != nil {
+		return false
+	}
+	return u.Host != "" && strings.EqualFold(u.Host, r.Host)
+}
+
+func availability(wp *tmdb.WatchProvidersResponse, up UserPrefs) *tmdb.Availability {
+	if wp == nil {
+		return nil
+	}
+	return wp.Availability(up.Region, up.Providers)
+}
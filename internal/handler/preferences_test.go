@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSaveSettings(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    int
+	}{
+		{"same origin", map[string]string{"Origin": "http://orbit.test"}, http.StatusSeeOther},
+		{"same origin referer", map[string]string{"Referer": "http://orbit.test/settings"}, http.StatusSeeOther},
+		{"no origin", nil, http.StatusSeeOther},
+		{"cross origin", map[string]string{"Origin": "https://evil.test"}, http.StatusForbidden},
+		{"cross origin referer", map[string]string{"Referer": "https://evil.test/page"}, http.StatusForbidden},
+		{"opaque origin", map[string]string{"Origin": "null"}, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "http://orbit.test/settings", strings.NewReader("region=DE&max_movie_rating=PG-13"))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			(&Handler{}).SaveSettings(w, r)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d", w.Code, tt.want)
+			}
+
+			cookies := w.Result().Cookies()
+			if tt.want != http.StatusSeeOther {
+				if len(cookies) > 0 {
+					t.Errorf("refused request set %d cookies", len(cookies))
+				}
+				return
+			}
+			if len(cookies) == 0 {
+				t.Fatal("no cookies set")
+			}
+			for _, c := range cookies {
+				if c.SameSite != http.SameSiteLaxMode || !c.HttpOnly {
+					t.Errorf("cookie %s: SameSite %v, HttpOnly %v", c.Name, c.SameSite, c.HttpOnly)
+				}
+			}
+		})
+	}
+}
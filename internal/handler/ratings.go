@@ -0,0 +1,325 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"regexp"
+	"sync"
+
+	"github.com/unedtamps/orbit/internal/tmdb"
+	jackett "github.com/webtor-io/go-jackett"
+)
+
+// ratingConcurrency bounds certification lookups while filtering a list.
+const ratingConcurrency = 6
+
+// adultMarker matches release titles that are explicitly adult content.
+var adultMarker = regexp.MustCompile(`(?i)(^|[^a-z0-9])(xxx|porn|hentai|jav|onlyfans|18\+)([^a-z0-9]|$)`)
+
+// ratingFilter hides titles rated above the strictest of the instance and
+// browser limits. Unrated titles are kept unless TMDB marks them adult.
+type ratingFilter struct {
+	client  *tmdb.Client
+	country string
+	// maxRank holds the limit per media type as a certification order;
+	// a missing entry means no limit.
+	maxRank map[string]int
+}
+
+func newRatingFilter(client *tmdb.Client, p Preferences, up UserPrefs) *ratingFilter {
+	f := &ratingFilter{client: client, country: p.CertificationCountry, maxRank: make(map[string]int)}
+	f.limit("movie", p.MaxMovieRating, up.MaxMovieRating)
+	f.limit("tv", p.MaxTVRating, up.MaxTVRating)
+	return f
+}
+
+func (f *ratingFilter) limit(mediaType string, ratings ...string) {
+	for _, rating := range ratings {
+		rank := f.client.CertificationRank(mediaType, f.country, rating)
+		if rank < 0 {
+			if rating != "" {
+				log.Printf("Unknown %s rating %q for %s, ignoring", mediaType, rating, f.country)
+			}
+			continue
+		}
+		if current, ok := f.maxRank[mediaType]; !ok || rank < current {
+			f.maxRank[mediaType] = rank
+		}
+	}
+}
+
+// active reports whether any limit applies, which is also when adult
+// content is hidden.
+func (f *ratingFilter) active() bool {
+	return len(f.maxRank) > 0
+}
+
+// allowsRating checks a known certification against the limit.
+func (f *ratingFilter) allowsRating(mediaType, cert string) bool {
+	max, ok := f.maxRank[mediaType]
+	if !ok {
+		return true
+	}
+	rank := f.client.CertificationRank(mediaType, f.country, cert)
+	return rank < 0 || rank <= max
+}
+
+// allows looks up a title's certification and checks it. Lookup failures
+// let the title through rather than emptying the page.
+func (f *ratingFilter) allows(mediaType string, id int, adult bool) bool {
+	if !f.active() {
+		return true
+	}
+	if adult {
+		return false
+	}
+	if _, ok := f.maxRank[mediaType]; !ok {
+		return true
+	}
+	cert, err := f.client.TitleCertification(mediaType, id, f.country)
+	if err != nil {
+		log.Printf("Certification lookup error for %s %d: %v", mediaType, id, err)
+		return true
+	}
+	return f.allowsRating(mediaType, cert)
+}
+
+// titleRef is a title of a list being filtered.
+type titleRef struct {
+	mediaType string
+	id        int
+	adult     bool
+}
+
+// allowed checks a list of titles, looking each distinct movie or show up
+// once with at most ratingConcurrency lookups in flight. Other media
+// types only have their adult flag checked.
+func (f *ratingFilter) allowed(refs []titleRef) []bool {
+	keep := make([]bool, len(refs))
+	if !f.active() {
+		for i := range keep {
+			keep[i] = true
+		}
+		return keep
+	}
+
+	verdicts := make(map[titleRef]*bool)
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, ratingConcurrency)
+	for _, ref := range refs {
+		if _, ok := verdicts[ref]; ok {
+			continue
+		}
+		verdict := new(bool)
+		verdicts[ref] = verdict
+		if ref.mediaType != "movie" && ref.mediaType != "tv" {
+			*verdict = !ref.adult
+			continue
+		}
+		wg.Add(1)
+		go func(ref titleRef) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			*verdict = f.allows(ref.mediaType, ref.id, ref.adult)
+		}(ref)
+	}
+	wg.Wait()
+
+	for i, ref := range refs {
+		keep[i] = *verdicts[ref]
+	}
+	return keep
+}
+
+// filter drops results above the limit, keeping order. Results without a
+// media type are treated as fallbackType.
+func (f *ratingFilter) filter(results []tmdb.SearchResult, fallbackType string) []tmdb.SearchResult {
+	if !f.active() || len(results) == 0 {
+		return results
+	}
+
+	refs := make([]titleRef, len(results))
+	for i, res := range results {
+		mediaType := res.MediaType
+		if mediaType == "" {
+			mediaType = fallbackType
+		}
+		refs[i] = titleRef{mediaType: mediaType, id: res.ID, adult: res.Adult}
+	}
+	keep := f.allowed(refs)
+
+	filtered := make([]tmdb.SearchResult, 0, len(results))
+	for i, res := range results {
+		if keep[i] {
+			filtered = append(filtered, res)
+		}
+	}
+	return filtered
+}
+
+// credits drops credits above the limit, keeping order.
+func (f *ratingFilter) credits(credits []tmdb.PersonCredit) []tmdb.PersonCredit {
+	if !f.active() || len(credits) == 0 {
+		return credits
+	}
+
+	refs := make([]titleRef, len(credits))
+	for i, c := range credits {
+		refs[i] = titleRef{mediaType: c.MediaType, id: c.ID, adult: c.Adult}
+	}
+	keep := f.allowed(refs)
+
+	filtered := make([]tmdb.PersonCredit, 0, len(credits))
+	for i, c := range credits {
+		if keep[i] {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered
+}
+
+// person filters a person's combined credits, and so their filmography,
+// in place. Titles in both lists are looked up once, the crew pass
+// hitting the certification cache.
+func (f *ratingFilter) person(p *tmdb.PersonDetails) {
+	if p.CombinedCredits == nil {
+		return
+	}
+	p.CombinedCredits.Cast = f.credits(p.CombinedCredits.Cast)
+	p.CombinedCredits.Crew = f.credits(p.CombinedCredits.Crew)
+}
+
+// collection filters a collection's parts in place and reports whether
+// any part was hidden.
+func (f *ratingFilter) collection(c *tmdb.Collection) (hidden bool) {
+	n := len(c.Parts)
+	c.Parts = f.filter(c.Parts, "movie")
+	return len(c.Parts) < n
+}
+
+// filterList filters a response's results in place and returns it.
+func (f *ratingFilter) filterList(resp *tmdb.MultiSearchResponse, fallbackType string) *tmdb.MultiSearchResponse {
+	if resp != nil {
+		resp.Results = f.filter(resp.Results, fallbackType)
+	}
+	return resp
+}
+
+// find filters a find lookup in place. Episodes and seasons are checked
+// against their show's rating.
+func (f *ratingFilter) find(resp *tmdb.FindResponse) {
+	if !f.active() {
+		return
+	}
+	resp.MovieResults = f.filter(resp.MovieResults, "movie")
+	resp.TVResults = f.filter(resp.TVResults, "tv")
+
+	refs := make([]titleRef, 0, len(resp.TVEpisodeResults)+len(resp.TVSeasonResults))
+	for _, e := range resp.TVEpisodeResults {
+		refs = append(refs, titleRef{mediaType: "tv", id: e.ShowID})
+	}
+	for _, s := range resp.TVSeasonResults {
+		refs = append(refs, titleRef{mediaType: "tv", id: s.ShowID})
+	}
+	keep := f.allowed(refs)
+
+	episodes := resp.TVEpisodeResults[:0]
+	for i, e := range resp.TVEpisodeResults {
+		if keep[i] {
+			episodes = append(episodes, e)
+		}
+	}
+	keep = keep[len(resp.TVEpisodeResults):]
+	seasons := resp.TVSeasonResults[:0]
+	for i, s := range resp.TVSeasonResults {
+		if keep[i] {
+			seasons = append(seasons, s)
+		}
+	}
+	resp.TVEpisodeResults, resp.TVSeasonResults = episodes, seasons
+}
+
+// calendar drops entries above the limit, and days left empty.
+func (f *ratingFilter) calendar(days []tmdb.CalendarDay) []tmdb.CalendarDay {
+	if !f.active() {
+		return days
+	}
+
+	var refs []titleRef
+	for _, d := range days {
+		for _, e := range d.Entries {
+			refs = append(refs, titleRef{mediaType: e.MediaType, id: e.ID})
+		}
+	}
+	keep := f.allowed(refs)
+
+	filtered := make([]tmdb.CalendarDay, 0, len(days))
+	for _, d := range days {
+		entries := make([]tmdb.CalendarEntry, 0, len(d.Entries))
+		for _, e := range d.Entries {
+			if keep[0] {
+				entries = append(entries, e)
+			}
+			keep = keep[1:]
+		}
+		if len(entries) > 0 {
+			filtered = append(filtered, tmdb.CalendarDay{Date: d.Date, Entries: entries})
+		}
+	}
+	return filtered
+}
+
+// movie reports whether a movie's details pass the filter, and filters
+// its embedded recommendation lists.
+func (f *ratingFilter) movie(m *tmdb.MovieDetails) bool {
+	if !f.active() {
+		return true
+	}
+	if m.Adult || !f.allowsRating("movie", m.Certification(f.country)) {
+		return false
+	}
+	f.filterList(m.Recommendations, "movie")
+	f.filterList(m.Similar, "movie")
+	return true
+}
+
+// tv reports whether a show's details pass the filter, and filters its
+// embedded recommendation lists.
+func (f *ratingFilter) tv(t *tmdb.TVDetails) bool {
+	if !f.active() {
+		return true
+	}
+	if !f.allowsRating("tv", t.Certification(f.country)) {
+		return false
+	}
+	f.filterList(t.Recommendations, "tv")
+	f.filterList(t.Similar, "tv")
+	return true
+}
+
+// releases drops torrent results whose titles carry adult markers.
+func (f *ratingFilter) releases(results []jackett.Result) []jackett.Result {
+	if !f.active() {
+		return results
+	}
+	filtered := make([]jackett.Result, 0, len(results))
+	for _, res := range results {
+		if f.allowsRelease(res.Title) {
+			filtered = append(filtered, res)
+		}
+	}
+	return filtered
+}
+
+// allowsRelease reports whether a release name passes the filter.
+func (f *ratingFilter) allowsRelease(title string) bool {
+	return !f.active() || !adultMarker.MatchString(title)
+}
+
+// ratingBlocked is the message shown for titles above the limit.
+const ratingBlocked = "This title is above the configured maximum rating"
+
+func writeRatingBlocked(w http.ResponseWriter, r *http.Request) {
+	writeError(w, r, ratingBlocked, http.StatusForbidden)
+}
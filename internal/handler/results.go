@@ -0,0 +1,263 @@
+package handler
+
+import (
+	"cmp"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/unedtamps/orbit/internal/model"
+	"github.com/unedtamps/orbit/internal/openapi"
+
+	jackett "github.com/webtor-io/go-jackett"
+)
+
+const (
+	// defaultPerPage is the page size of the JSON search APIs.
+	defaultPerPage = 50
+	// magnetPerPage is the page size of the HTML magnet results.
+	magnetPerPage = 10
+	maxPerPage    = 100
+)
+
+// releaseParams are the query parameters a releaseQuery reads. They only
+// change how results are shown, never which search runs.
+var releaseParams = openapi.QueryParams(releaseRequest{})
+
+var sizeRe = regexp.MustCompile(`(?i)^\s*(\d+(?:\.\d+)?)\s*(b|kb|mb|gb|tb)?\s*$`)
+
+// Codecs a release can be filtered by, as model.ParseCodec names them.
+var releaseCodecs = []string{"x264", "x265", "av1", "vp9", "xvid"}
+
+// releaseResolutions are the resolutions releases can be filtered by, best
+// first, as model.ParseResolution names them.
+var releaseResolutions = []string{"2160p", "1080p", "720p", "576p", "480p"}
+
+// releaseQuery filters, sorts and pages torrent results.
+type releaseQuery struct {
+	MinSeeders     uint
+	MinSize        uint64
+	MaxSize        uint64
+	Trackers       []string
+	PublishedAfter time.Time
+	Resolutions    []string
+	Codecs         []string
+	Sort           string
+	Desc           bool
+	Page           int
+	PerPage        int
+}
+
+// query reads a releaseQuery from the request's filter, sort and page
+// parameters. Sizes take KB, MB, GB or TB; 4k and uhd mean 2160p.
+func (req releaseRequest) query(perPage int) (releaseQuery, error) {
+	q := releaseQuery{MinSeeders: req.MinSeeders, Sort: "seeders", Desc: true, Page: 1, PerPage: perPage}
+
+	var err error
+	if q.MinSize, err = parseSize(req.MinSize); err != nil {
+		return q, fmt.Errorf("invalid min_size: %w", err)
+	}
+	if q.MaxSize, err = parseSize(req.MaxSize); err != nil {
+		return q, fmt.Errorf("invalid max_size: %w", err)
+	}
+	if s := req.PublishedAfter; s != "" {
+		if q.PublishedAfter, err = time.Parse("2006-01-02", s); err != nil {
+			if q.PublishedAfter, err = time.Parse(time.RFC3339, s); err != nil {
+				return q, fmt.Errorf("invalid published_after: %q (use YYYY-MM-DD)", s)
+			}
+		}
+	}
+
+	for _, t := range req.Tracker {
+		q.Trackers = append(q.Trackers, strings.ToLower(t))
+	}
+	for _, r := range req.Resolution {
+		r = strings.ToLower(r)
+		if r == "4k" || r == "uhd" {
+			r = "2160p"
+		}
+		q.Resolutions = append(q.Resolutions, r)
+	}
+	for _, c := range req.Codec {
+		codec := model.ParseCodec(strings.ToLower(c))
+		if codec == "" {
+			return q, fmt.Errorf("invalid codec: %q", c)
+		}
+		q.Codecs = append(q.Codecs, codec)
+	}
+
+	// Decoding already held sort and order to their enums.
+	if req.Sort != "" {
+		q.Sort = req.Sort
+		q.Desc = req.Sort != "title"
+	}
+	switch req.Order {
+	case "asc":
+		q.Desc = false
+	case "desc":
+		q.Desc = true
+	}
+
+	if req.Page < 0 {
+		return q, fmt.Errorf("invalid page: %d", req.Page)
+	} else if req.Page > 0 {
+		q.Page = req.Page
+	}
+	if req.PerPage < 0 || req.PerPage > maxPerPage {
+		return q, fmt.Errorf("invalid per_page: %d (1 to %d)", req.PerPage, maxPerPage)
+	} else if req.PerPage > 0 {
+		q.PerPage = req.PerPage
+	}
+	return q, nil
+}
+
+// parseSize reads a size such as 700MB or 4.5GB, in bytes. Plain numbers
+// are bytes; "" is 0.
+func parseSize(s string) (uint64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	m := sizeRe.FindStringSubmatch(s)
+	if m == nil {
+		return 0, fmt.Errorf("%q", s)
+	}
+	n, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q", s)
+	}
+	units := map[string]float64{"": 1, "b": 1, "kb": 1 << 10, "mb": 1 << 20, "gb": 1 << 30, "tb": 1 << 40}
+	return uint64(n * units[strings.ToLower(m[2])]), nil
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// keep reports whether a release passes the filters.
+func (q releaseQuery) keep(r *jackett.Result) bool {
+	switch {
+	case r.Seeders < q.MinSeeders:
+		return false
+	case q.MinSize > 0 && r.Size < q.MinSize:
+		return false
+	case q.MaxSize > 0 && r.Size > q.MaxSize:
+		return false
+	case !q.PublishedAfter.IsZero() && r.PublishDate.Before(q.PublishedAfter):
+		return false
+	case len(q.Trackers) > 0 && !contains(q.Trackers, strings.ToLower(r.Tracker)):
+		return false
+	case len(q.Resolutions) > 0 && !contains(q.Resolutions, model.ParseResolution(r.Title)):
+		return false
+	case len(q.Codecs) > 0 && !contains(q.Codecs, model.ParseCodec(r.Title)):
+		return false
+	}
+	return true
+}
+
+// less orders releases by the sort key, falling back to seeders and then
+// peers, most first, so ties keep the usual order.
+func (q releaseQuery) less(a, b *jackett.Result) bool {
+	var c int
+	switch q.Sort {
+	case "seeders":
+		c = cmp.Compare(a.Seeders, b.Seeders)
+	case "peers":
+		c = cmp.Compare(a.Peers, b.Peers)
+	case "size":
+		c = cmp.Compare(a.Size, b.Size)
+	case "date":
+		c = a.PublishDate.Compare(b.PublishDate)
+	case "title":
+		c = strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+	}
+	if c != 0 {
+		if q.Desc {
+			return c > 0
+		}
+		return c < 0
+	}
+	if c = cmp.Compare(a.Seeders, b.Seeders); c != 0 {
+		return c > 0
+	}
+	return a.Peers > b.Peers
+}
+
+// apply filters and sorts items, whose releases get returns, and cuts out
+// the requested page. total counts every item that passed the filters.
+func apply[T any](items []T, get func(*T) *jackett.Result, q releaseQuery) (page []T, total int) {
+	kept := make([]T, 0, len(items))
+	for i := range items {
+		if q.keep(get(&items[i])) {
+			kept = append(kept, items[i])
+		}
+	}
+	sort.SliceStable(kept, func(i, j int) bool { return q.less(get(&kept[i]), get(&kept[j])) })
+
+	start := (q.Page - 1) * q.PerPage
+	if start >= len(kept) {
+		return []T{}, len(kept)
+	}
+	end := start + q.PerPage
+	if end > len(kept) {
+		end = len(kept)
+	}
+	return kept[start:end], len(kept)
+}
+
+// totalPages is how many pages total items fill, at least 1.
+func (q releaseQuery) totalPages(total int) int {
+	if total == 0 {
+		return 1
+	}
+	return (total + q.PerPage - 1) / q.PerPage
+}
+
+// searchParams drops the releaseQuery parameters, leaving those that
+// decide which search runs.
+func searchParams(v url.Values) url.Values {
+	return splitParams(v, false)
+}
+
+// viewParams keeps only the releaseQuery parameters.
+func viewParams(v url.Values) url.Values {
+	return splitParams(v, true)
+}
+
+func splitParams(v url.Values, view bool) url.Values {
+	out := url.Values{}
+	for key, values := range v {
+		if contains(releaseParams, key) == view {
+			out[key] = values
+		}
+	}
+	return out
+}
+
+// ReleasePage is one page of torrent results.
+type ReleasePage struct {
+	Page         int             `json:"page"`
+	PerPage      int             `json:"per_page"`
+	TotalResults int             `json:"total_results"`
+	TotalPages   int             `json:"total_pages"`
+	Results      []model.Release `json:"results"`
+}
+
+func releasePage(results []jackett.Result, q releaseQuery) ReleasePage {
+	page, total := apply(results, func(r *jackett.Result) *jackett.Result { return r }, q)
+	return ReleasePage{
+		Page:         q.Page,
+		PerPage:      q.PerPage,
+		TotalResults: total,
+		TotalPages:   q.totalPages(total),
+		Results:      model.FromJackettAll(page),
+	}
+}
@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	jackett "github.com/webtor-io/go-jackett"
+)
+
+func TestReleaseRequestQuery(t *testing.T) {
+	defaults := releaseQuery{Sort: "seeders", Desc: true, Page: 1, PerPage: 10}
+	with := func(edit func(q *releaseQuery)) releaseQuery {
+		q := defaults
+		edit(&q)
+		return q
+	}
+	tests := []struct {
+		query   string
+		want    releaseQuery
+		wantErr string
+	}{
+		{query: "", want: defaults},
+		{query: "min_seeders=5&min_size=700MB&max_size=4.5gb", want: with(func(q *releaseQuery) {
+			q.MinSeeders, q.MinSize, q.MaxSize = 5, 700<<20, 4.5*(1<<30)
+		})},
+		{query: "tracker=RARBG,1337x&tracker=YTS", want: with(func(q *releaseQuery) {
+			q.Trackers = []string{"rarbg", "1337x", "yts"}
+		})},
+		{query: "resolution=4K,1080p&codec=HEVC,h264", want: with(func(q *releaseQuery) {
+			q.Resolutions = []string{"2160p", "1080p"}
+			q.Codecs = []string{"x265", "x264"}
+		})},
+		{query: "published_after=2024-05-01", want: with(func(q *releaseQuery) {
+			q.PublishedAfter = time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+		})},
+		{query: "sort=title", want: with(func(q *releaseQuery) { q.Sort, q.Desc = "title", false })},
+		{query: "sort=title&order=desc", want: with(func(q *releaseQuery) { q.Sort = "title" })},
+		{query: "sort=size&order=asc", want: with(func(q *releaseQuery) { q.Sort, q.Desc = "size", false })},
+		{query: "page=3&per_page=100", want: with(func(q *releaseQuery) { q.Page, q.PerPage = 3, 100 })},
+
+		{query: "min_seeders=-1", wantErr: `invalid min_seeders: "-1"`},
+		{query: "min_size=lots", wantErr: `invalid min_size: "lots"`},
+		{query: "codec=mpeg2", wantErr: `invalid codec: "mpeg2"`},
+		{query: "published_after=May", wantErr: `invalid published_after: "May" (use YYYY-MM-DD)`},
+		{query: "sort=rating", wantErr: `invalid sort: "rating" (use seeders, peers, size, date or title)`},
+		{query: "order=up", wantErr: `invalid order: "up" (use asc or desc)`},
+		{query: "page=-2", wantErr: "invalid page: -2"},
+		{query: "per_page=500", wantErr: "invalid per_page: 500 (1 to 100)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var req releaseRequest
+			err := decodeRequest(httptest.NewRequest("GET", "/?"+tt.query, nil), &req)
+			var got releaseQuery
+			if err == nil {
+				got, err = req.query(10)
+			}
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("query = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReleaseParams(t *testing.T) {
+	want := []string{"min_seeders", "min_size", "max_size", "tracker", "published_after",
+		"resolution", "codec", "sort", "order", "page", "per_page"}
+	if !reflect.DeepEqual(releaseParams, want) {
+		t.Errorf("releaseParams = %v, want %v", releaseParams, want)
+	}
+}
+
+func TestApply(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, time.May, d, 0, 0, 0, 0, time.UTC) }
+	results := []jackett.Result{
+		{Title: "Film.2001.1080p.x264-A", Tracker: "RARBG", Size: 2 << 30, Seeders: 50, Peers: 5, PublishDate: day(1)},
+		{Title: "Film.2001.2160p.x265-B", Tracker: "YTS", Size: 8 << 30, Seeders: 80, Peers: 2, PublishDate: day(3)},
+		{Title: "Film.2001.720p.x264-C", Tracker: "1337x", Size: 1 << 30, Seeders: 5, Peers: 9, PublishDate: day(2)},
+		{Title: "Film.2001.1080p.x265-D", Tracker: "YTS", Size: 3 << 30, Seeders: 50, Peers: 7, PublishDate: day(4)},
+	}
+	q := func(edit func(q *releaseQuery)) releaseQuery {
+		q := releaseQuery{Sort: "seeders", Desc: true, Page: 1, PerPage: 10}
+		edit(&q)
+		return q
+	}
+	tests := []struct {
+		name      string
+		q         releaseQuery
+		want      []string
+		wantTotal int
+	}{
+		// Seeder ties fall back to peers.
+		{"default order", q(func(*releaseQuery) {}), []string{"B", "D", "A", "C"}, 4},
+		{"min seeders", q(func(q *releaseQuery) { q.MinSeeders = 50 }), []string{"B", "D", "A"}, 3},
+		{"size range", q(func(q *releaseQuery) { q.MinSize, q.MaxSize = 2<<30, 4<<30 }), []string{"D", "A"}, 2},
+		{"tracker", q(func(q *releaseQuery) { q.Trackers = []string{"yts"} }), []string{"B", "D"}, 2},
+		{"resolution", q(func(q *releaseQuery) { q.Resolutions = []string{"1080p"} }), []string{"D", "A"}, 2},
+		{"codec", q(func(q *releaseQuery) { q.Codecs = []string{"x264"} }), []string{"A", "C"}, 2},
+		{"published after", q(func(q *releaseQuery) { q.PublishedAfter = day(3) }), []string{"B", "D"}, 2},
+		{"size ascending", q(func(q *releaseQuery) { q.Sort, q.Desc = "size", false }), []string{"C", "A", "D", "B"}, 4},
+		{"newest first", q(func(q *releaseQuery) { q.Sort = "date" }), []string{"D", "B", "C", "A"}, 4},
+		{"title", q(func(q *releaseQuery) { q.Sort, q.Desc = "title", false }), []string{"A", "D", "B", "C"}, 4},
+		{"second page", q(func(q *releaseQuery) { q.Page, q.PerPage = 2, 3 }), []string{"C"}, 4},
+		{"past the end", q(func(q *releaseQuery) { q.Page, q.PerPage = 3, 3 }), []string{}, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, total := apply(results, func(r *jackett.Result) *jackett.Result { return r }, tt.q)
+			got := make([]string, len(page))
+			for i, r := range page {
+				got[i] = r.Title[strings.LastIndexByte(r.Title, '-')+1:]
+			}
+			if !reflect.DeepEqual(got, tt.want) || total != tt.wantTotal {
+				t.Errorf("apply = %v (%d total), want %v (%d total)", got, total, tt.want, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestTotalPages(t *testing.T) {
+	q := releaseQuery{PerPage: 10}
+	for total, want := range map[int]int{0: 1, 1: 1, 10: 1, 11: 2, 95: 10} {
+		if got := q.totalPages(total); got != want {
+			t.Errorf("totalPages(%d) = %d, want %d", total, got, want)
+		}
+	}
+}
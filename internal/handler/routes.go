@@ -3,48 +3,64 @@ package handler
 import (
 	"encoding/json"
 	"net/http"
-
-	"github.com/go-chi/chi/v5"
 )
 
-// GetMovies godoc
-//
-//	@Summary		Get Movies
-//	@Description	Get Movies (JSON API)
-//	@Tags			movies
-//	@Produce		json
-//	@Param			query	path	string	true	"Search query"
-//	@Success		200	{array}	jackett.Result
-//	@Router			/api/movies/search/{query} [get]
+// GetMovies searches the movie categories and returns a page of releases.
 func (h *Handler) GetMovies(w http.ResponseWriter, r *http.Request) {
-	query := chi.URLParam(r, "query")
+	query, q, ok := torrentSearch(w, r)
+	if !ok {
+		return
+	}
 	results, err := h.fetcher.FetchMovies(r.Context(), query)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	w.WriteHeader(http.StatusOK)
-	writeJSON(w, results)
+	writeJSON(w, releasePage(h.ratings(r).releases(results), q))
 }
 
-// GetTV godoc
-//
-//	@Summary		Get TV Series
-//	@Description	Get TV Series (JSON API)
-//	@Tags			tv
-//	@Produce		json
-//	@Param			query	path	string	true	"Search query"
-//	@Success		200	{array}	jackett.Result
-//	@Router			/api/tv/search/{query} [get]
+// GetTV searches the TV categories and returns a page of releases.
 func (h *Handler) GetTV(w http.ResponseWriter, r *http.Request) {
-	query := chi.URLParam(r, "query")
+	query, q, ok := torrentSearch(w, r)
+	if !ok {
+		return
+	}
 	results, err := h.fetcher.FetchTV(r.Context(), query)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	w.WriteHeader(http.StatusOK)
-	writeJSON(w, results)
+	writeJSON(w, releasePage(h.ratings(r).releases(results), q))
+}
+
+// GetAnime searches the anime categories only and returns a page of
+// releases.
+func (h *Handler) GetAnime(w http.ResponseWriter, r *http.Request) {
+	query, q, ok := torrentSearch(w, r)
+	if !ok {
+		return
+	}
+	results, err := h.fetcher.FetchAnime(r.Context(), query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, releasePage(h.ratings(r).releases(results), q))
+}
+
+// torrentSearch reads a torrent search request, writing the error itself
+// when ok is false.
+func torrentSearch(w http.ResponseWriter, r *http.Request) (query string, q releaseQuery, ok bool) {
+	var req torrentSearchRequest
+	err := decodeRequest(r, &req)
+	if err == nil {
+		q, err = req.query(defaultPerPage)
+	}
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return "", q, false
+	}
+	return req.Query, q, true
 }
 
 func writeJSON(w http.ResponseWriter, v interface{}) {
@@ -0,0 +1,296 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/unedtamps/orbit/internal/fetcher"
+	"github.com/unedtamps/orbit/internal/tmdb"
+
+	jackett "github.com/webtor-io/go-jackett"
+)
+
+const (
+	// streamConcurrency is how many searches a stream runs at once.
+	streamConcurrency = 6
+	// streamUnitLimit caps the searches one stream may start. Each unit
+	// is one indexer request, so streams run fewer strategies the more
+	// indexers are configured.
+	streamUnitLimit = 24
+)
+
+// Stream event names.
+const (
+	EventProgress     = "progress"
+	EventResults      = "results"
+	EventIndexerError = "indexer-error"
+	EventDone         = "done"
+)
+
+// StreamProgress is sent after every search a stream finishes.
+type StreamProgress struct {
+	Done     int    `json:"done"`
+	Total    int    `json:"total"`
+	Strategy string `json:"strategy"`
+	Indexer  string `json:"indexer,omitempty"`
+	Found    int    `json:"found"`
+	Relevant int    `json:"relevant"`
+}
+
+// StreamError is sent when one indexer's search fails.
+type StreamError struct {
+	Indexer  string `json:"indexer,omitempty"`
+	Strategy string `json:"strategy"`
+	Error    string `json:"error"`
+}
+
+// StreamDone ends a stream.
+type StreamDone struct {
+	Hits         int  `json:"hits"`
+	Relevant     int  `json:"relevant"`
+	Ran          int  `json:"ran"`
+	Failed       int  `json:"failed"`
+	StoppedEarly bool `json:"stopped_early"`
+}
+
+// streamUnit is one search: a strategy on one indexer, or on all of them
+// when the indexer is unset.
+type streamUnit struct {
+	strategy strategy
+	indexer  fetcher.Indexer
+}
+
+type unitResult struct {
+	unit  streamUnit
+	found []jackett.Result
+	err   error
+}
+
+func streamRequested(r *http.Request) bool {
+	return r.URL.Query().Get("stream") == "1"
+}
+
+// writeStreamShell renders the results container that opens the event
+// stream for the same request. Once the stream is done the container
+// swaps itself for the paged results, which come from the hit cache.
+func (h *MagnetHandler) writeStreamShell(w http.ResponseWriter, r *http.Request, warning *tmdb.ReleaseSchedule) {
+	q := r.URL.Query()
+	q.Del("stream")
+	data := map[string]interface{}{
+		"StreamURL": strings.TrimSuffix(r.URL.Path, "/") + "/stream?" + q.Encode(),
+		"PagedURL":  r.URL.Path + "?" + q.Encode(),
+		"Warning":   warning,
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := h.template.ExecuteTemplate(w, "magnet_stream.html", data); err != nil {
+		log.Printf("Magnet template error: %v", err)
+		http.Error(w, "Failed to render results", http.StatusInternalServerError)
+	}
+}
+
+// sseWriter writes server-sent events, flushing after each.
+type sseWriter struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+func (s *sseWriter) send(event, data string) {
+	fmt.Fprintf(s.w, "event: %s\n", event)
+	for _, line := range strings.Split(data, "\n") {
+		fmt.Fprintf(s.w, "data: %s\n", line)
+	}
+	fmt.Fprint(s.w, "\n")
+	s.flusher.Flush()
+}
+
+func (s *sseWriter) sendJSON(event string, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		log.Printf("Stream encode error: %v", err)
+		return
+	}
+	s.send(event, string(data))
+}
+
+// streamUnits splits the plan's strategies, within its budget, across
+// the configured indexers, keeping whole strategies in rank order while
+// they fit in streamUnitLimit. The top strategy always runs. When the
+// indexer list is unavailable every strategy searches all indexers at
+// once, as run does.
+func (h *MagnetHandler) streamUnits(ctx context.Context, p *searchPlan) []streamUnit {
+	strategies := p.strategies
+	if len(strategies) > planBudget {
+		strategies = strategies[:planBudget]
+	}
+	indexers, err := h.fetcher.Indexers(ctx)
+	if err != nil {
+		log.Printf("Indexer list error: %v", err)
+	}
+
+	perStrategy := len(indexers)
+	if perStrategy == 0 {
+		perStrategy = 1
+	}
+	if n := max(1, streamUnitLimit/perStrategy); len(strategies) > n {
+		strategies = strategies[:n]
+	}
+
+	var units []streamUnit
+	for _, s := range strategies {
+		if len(indexers) == 0 {
+			units = append(units, streamUnit{strategy: s})
+			continue
+		}
+		for _, idx := range indexers {
+			units = append(units, streamUnit{strategy: s, indexer: idx})
+		}
+	}
+	return units
+}
+
+func (h *MagnetHandler) searchUnit(ctx context.Context, u streamUnit) ([]jackett.Result, error) {
+	s, indexer := u.strategy, u.indexer.ID
+	switch {
+	case indexer == "":
+		return h.search(ctx, s)
+	case s.id != nil:
+		return h.fetcher.SearchByIDOn(ctx, indexer, *s.id)
+	case s.anime:
+		return h.fetcher.FetchAnimeOn(ctx, indexer, s.query)
+	default:
+		return h.fetcher.SearchOn(ctx, indexer, s.query)
+	}
+}
+
+// stream runs a plan like run, but sends each search's new releases as
+// soon as it finishes, with progress and per-indexer errors as events.
+// Hits are credited to whichever strategy returned them first. Once
+// planEnough relevant hits are in, searches still running are cancelled.
+func (h *MagnetHandler) stream(w http.ResponseWriter, r *http.Request, p *searchPlan) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	sse := &sseWriter{w: w, flusher: flusher}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	units := h.streamUnits(ctx, p)
+	rf := h.ratings(r)
+
+	results := make(chan unitResult)
+	go func() {
+		sem := make(chan struct{}, streamConcurrency)
+		var wg sync.WaitGroup
+	dispatch:
+		for _, u := range units {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				break dispatch
+			}
+			wg.Add(1)
+			go func(u streamUnit) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				found, err := h.searchUnit(ctx, u)
+				select {
+				case results <- unitResult{unit: u, found: found, err: err}:
+				case <-ctx.Done():
+				}
+			}(u)
+		}
+		wg.Wait()
+		close(results)
+	}()
+
+	seen := make(map[string]bool)
+	var all []MagnetHit
+	var done StreamDone
+	for res := range results {
+		if res.err != nil && ctx.Err() != nil {
+			continue
+		}
+		done.Ran++
+		s := res.unit.strategy
+		if res.err != nil {
+			done.Failed++
+			log.Printf("Magnet stream error (%s %q on %s): %v", s.name, s.query, res.unit.indexer.ID, res.err)
+			sse.sendJSON(EventIndexerError, StreamError{Indexer: res.unit.indexer.Title, Strategy: s.name, Error: res.err.Error()})
+		} else {
+			var batch []MagnetHit
+			for _, rel := range res.found {
+				key := dedupeKey(rel)
+				if seen[key] {
+					continue
+				}
+				hit, relevant, keep := p.hit(s, rel)
+				if !keep {
+					continue
+				}
+				seen[key] = true
+				if relevant {
+					done.Relevant++
+				}
+				all = append(all, hit)
+				if rf.allowsRelease(rel.Title) {
+					batch = append(batch, hit)
+				}
+			}
+			if len(batch) > 0 {
+				done.Hits += len(batch)
+				sortHits(batch)
+				h.sendHits(sse, r, batch)
+			}
+		}
+
+		sse.sendJSON(EventProgress, StreamProgress{
+			Done:     done.Ran,
+			Total:    len(units),
+			Strategy: s.name,
+			Indexer:  res.unit.indexer.Title,
+			Found:    done.Hits,
+			Relevant: done.Relevant,
+		})
+		if done.Relevant >= planEnough && !done.StoppedEarly {
+			done.StoppedEarly = true
+			cancel()
+		}
+	}
+
+	log.Printf("Magnet stream: ran %d of %d searches, %d hits, %d relevant", done.Ran, len(units), done.Hits, done.Relevant)
+	if r.Context().Err() == nil && done.Ran > done.Failed {
+		sortHits(all)
+		h.hits.Put(hitKey(r), all)
+	}
+	sse.sendJSON(EventDone, done)
+}
+
+// sendHits sends a batch of hits as one results event: releases as JSON
+// when the request asked for JSON, and otherwise the same markup as the
+// full results.
+func (h *MagnetHandler) sendHits(sse *sseWriter, r *http.Request, hits []MagnetHit) {
+	if wantsJSON(r) {
+		sse.sendJSON(EventResults, hits)
+		return
+	}
+	var buf bytes.Buffer
+	if err := h.template.ExecuteTemplate(&buf, "magnet_items.html", hits); err != nil {
+		log.Printf("Magnet template error: %v", err)
+		return
+	}
+	sse.send(EventResults, buf.String())
+}
@@ -2,30 +2,112 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
-	"strconv"
 	"strings"
+	"time"
 
-	"github.com/go-chi/chi/v5"
+	"github.com/unedtamps/orbit/internal/imdb"
+	"github.com/unedtamps/orbit/internal/metadata"
 	"github.com/unedtamps/orbit/internal/tmdb"
 )
 
 type TMDBHandler struct {
 	client *tmdb.Client
+	meta   *metadata.Chain
+	imdb   *imdb.Store
+	prefs  Preferences
 }
 
-func NewTMDBHandler(client *tmdb.Client) *TMDBHandler {
-	return &TMDBHandler{client: client}
+func NewTMDBHandler(client *tmdb.Client, meta *metadata.Chain, store *imdb.Store, prefs Preferences) *TMDBHandler {
+	return &TMDBHandler{client: client, meta: meta, imdb: store, prefs: prefs}
+}
+
+type searchResponse struct {
+	*tmdb.MultiSearchResponse
+	Provenance *metadata.Provenance `json:"provenance,omitempty"`
+}
+
+type movieResponse struct {
+	*tmdb.MovieDetails
+	Availability    *tmdb.Availability    `json:"availability,omitempty"`
+	ReleaseSchedule *tmdb.ReleaseSchedule `json:"release_schedule,omitempty"`
+	IMDbRating      *imdb.Rating          `json:"imdb_rating,omitempty"`
+	Provenance      *metadata.Provenance  `json:"provenance,omitempty"`
+}
+
+type tvResponse struct {
+	*tmdb.TVDetails
+	Availability *tmdb.Availability   `json:"availability,omitempty"`
+	IMDbRating   *imdb.Rating         `json:"imdb_rating,omitempty"`
+	Provenance   *metadata.Provenance `json:"provenance,omitempty"`
+}
+
+type seasonResponse struct {
+	*tmdb.SeasonDetails
+	IMDbEpisodes map[int]imdb.Episode `json:"imdb_episodes,omitempty"`
+	Provenance   *metadata.Provenance `json:"provenance,omitempty"`
+}
+
+type watchProvidersResponse struct {
+	*tmdb.WatchProvidersResponse
+	Availability *tmdb.Availability `json:"availability,omitempty"`
+}
+
+type releaseDatesResponse struct {
+	ID       int                    `json:"id"`
+	Region   string                 `json:"region"`
+	Dates    []tmdb.ReleaseDate     `json:"dates"`
+	Schedule *tmdb.ReleaseSchedule  `json:"schedule"`
+	Results  []tmdb.CountryReleases `json:"results"`
+}
+
+type providerListResponse struct {
+	Region  string          `json:"region"`
+	Results []tmdb.Provider `json:"results"`
+}
+
+type regionsResponse struct {
+	Results []tmdb.WatchRegion `json:"results"`
+}
+
+type genresResponse struct {
+	Language string       `json:"language"`
+	Movie    []tmdb.Genre `json:"movie"`
+	TV       []tmdb.Genre `json:"tv"`
+}
+
+type genreTitlesResponse struct {
+	ID           int                 `json:"id"`
+	Name         string              `json:"name"`
+	MediaType    string              `json:"media_type"`
+	Page         int                 `json:"page"`
+	TotalPages   int                 `json:"total_pages"`
+	TotalResults int                 `json:"total_results"`
+	Results      []tmdb.SearchResult `json:"results"`
+}
+
+type calendarResponse struct {
+	Region  string             `json:"region"`
+	Days    int                `json:"days"`
+	Results []tmdb.CalendarDay `json:"results"`
+}
+
+func (h *TMDBHandler) ratings(r *http.Request) *ratingFilter {
+	return newRatingFilter(h.client, h.prefs, h.prefs.resolve(r))
 }
 
 func writeJSONError(w http.ResponseWriter, msg string, status int) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(map[string]string{"error": msg})
+	json.NewEncoder(w).Encode(errorResponse{Error: msg})
 }
 
 func cleanTMDBError(err error) string {
+	if errors.Is(err, tmdb.ErrNoAPIKey) {
+		return "TMDB is not configured on this server."
+	}
 	msg := err.Error()
 	if strings.Contains(msg, "context deadline exceeded") || strings.Contains(msg, "Client.Timeout") {
 		return "TMDB API timed out. Please try again."
@@ -43,18 +125,13 @@ func cleanTMDBError(err error) string {
 }
 
 func (h *TMDBHandler) Search(w http.ResponseWriter, r *http.Request) {
-	query := r.URL.Query().Get("q")
-	if query == "" {
-		writeJSONError(w, "query parameter q is required", http.StatusBadRequest)
+	var req searchRequest
+	if err := decodeRequest(r, &req); err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
-	if page < 1 {
-		page = 1
-	}
-
-	result, err := h.client.MultiSearch(query, page)
+	result, prov, err := h.meta.MultiSearch(req.Query, req.Page)
 	if err != nil {
 		log.Printf("TMDB search error: %v", err)
 		writeJSONError(w, cleanTMDBError(err), http.StatusBadGateway)
@@ -62,80 +139,136 @@ func (h *TMDBHandler) Search(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(result)
+	json.NewEncoder(w).Encode(searchResponse{
+		MultiSearchResponse: h.ratings(r).filterList(result, ""),
+		Provenance:          prov,
+	})
 }
 
 func (h *TMDBHandler) GetMovie(w http.ResponseWriter, r *http.Request) {
-	id, err := strconv.Atoi(chi.URLParam(r, "id"))
-	if err != nil {
-		writeJSONError(w, "invalid movie id", http.StatusBadRequest)
+	var req idRegionRequest
+	if err := decodeRequest(r, &req); err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	result, err := h.client.GetMovieDetails(id)
+	result, prov, err := h.meta.GetMovieDetails(req.ID)
 	if err != nil {
 		log.Printf("TMDB movie error: %v", err)
 		writeJSONError(w, cleanTMDBError(err), http.StatusBadGateway)
 		return
 	}
+	if !h.ratings(r).movie(result) {
+		writeJSONError(w, ratingBlocked, http.StatusForbidden)
+		return
+	}
 
+	up := h.prefs.resolve(r)
+	_, rating := movieIMDb(h.imdb, result)
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(result)
+	json.NewEncoder(w).Encode(movieResponse{
+		MovieDetails:    result,
+		Availability:    availability(result.WatchProviders, up),
+		ReleaseSchedule: result.Schedule(up.Region, time.Now()),
+		IMDbRating:      rating,
+		Provenance:      prov,
+	})
 }
 
-func (h *TMDBHandler) GetTV(w http.ResponseWriter, r *http.Request) {
-	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+func (h *TMDBHandler) GetMovieReleaseDates(w http.ResponseWriter, r *http.Request) {
+	var req idRegionRequest
+	if err := decodeRequest(r, &req); err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.client.GetMovieReleaseDates(req.ID)
 	if err != nil {
-		writeJSONError(w, "invalid tv id", http.StatusBadRequest)
+		log.Printf("TMDB movie release dates error: %v", err)
+		writeJSONError(w, cleanTMDBError(err), http.StatusBadGateway)
+		return
+	}
+
+	region := h.prefs.resolve(r).Region
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(releaseDatesResponse{
+		ID:       result.ID,
+		Region:   region,
+		Dates:    result.Region(region),
+		Schedule: result.Schedule(region, time.Now()),
+		Results:  result.Results,
+	})
+}
+
+func (h *TMDBHandler) GetTV(w http.ResponseWriter, r *http.Request) {
+	var req idRegionRequest
+	if err := decodeRequest(r, &req); err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	result, err := h.client.GetTVDetails(id)
+	result, prov, err := h.meta.GetTVDetails(req.ID)
 	if err != nil {
 		log.Printf("TMDB TV error: %v", err)
 		writeJSONError(w, cleanTMDBError(err), http.StatusBadGateway)
 		return
 	}
+	if !h.ratings(r).tv(result) {
+		writeJSONError(w, ratingBlocked, http.StatusForbidden)
+		return
+	}
 
+	_, rating := tvIMDb(h.imdb, result)
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(result)
+	json.NewEncoder(w).Encode(tvResponse{
+		TVDetails:    result,
+		Availability: availability(result.WatchProviders, h.prefs.resolve(r)),
+		IMDbRating:   rating,
+		Provenance:   prov,
+	})
 }
 
 func (h *TMDBHandler) GetSeason(w http.ResponseWriter, r *http.Request) {
-	tvID, err := strconv.Atoi(chi.URLParam(r, "id"))
-	if err != nil {
-		writeJSONError(w, "invalid tv_id", http.StatusBadRequest)
+	var req seasonRequest
+	if err := decodeRequest(r, &req); err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	season, err := strconv.Atoi(chi.URLParam(r, "season"))
-	if err != nil {
-		writeJSONError(w, "invalid season number", http.StatusBadRequest)
+
+	if !h.ratings(r).allows("tv", req.ID, false) {
+		writeJSONError(w, ratingBlocked, http.StatusForbidden)
 		return
 	}
 
-	result, err := h.client.GetSeasonDetails(tvID, season)
+	result, prov, err := h.meta.GetSeasonDetails(req.ID, req.Season)
 	if err != nil {
 		log.Printf("TMDB season error: %v", err)
 		writeJSONError(w, cleanTMDBError(err), http.StatusBadGateway)
 		return
 	}
 
+	resp := seasonResponse{SeasonDetails: result, Provenance: prov}
+	// Only worth a TMDB round trip for the show's IMDb ID when there is a
+	// dataset to look its episodes up in.
+	if h.imdb != nil {
+		if ext, err := h.client.GetTVExternalIDs(req.ID); err == nil {
+			id, _ := imdbRating(h.imdb, ext.IMDbID, imdb.Title.IsSeries)
+			resp.IMDbEpisodes = h.imdb.Season(id, req.Season)
+		}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(result)
+	json.NewEncoder(w).Encode(resp)
 }
 
 func (h *TMDBHandler) GetMovieReviews(w http.ResponseWriter, r *http.Request) {
-	id, err := strconv.Atoi(chi.URLParam(r, "id"))
-	if err != nil {
-		writeJSONError(w, "invalid movie id", http.StatusBadRequest)
+	var req idPageRequest
+	if err := decodeRequest(r, &req); err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
-	if page < 1 {
-		page = 1
-	}
 
-	result, err := h.client.GetMovieReviews(id, page)
+	result, err := h.client.GetMovieReviews(req.ID, req.Page)
 	if err != nil {
 		log.Printf("TMDB movie reviews error: %v", err)
 		writeJSONError(w, cleanTMDBError(err), http.StatusBadGateway)
@@ -147,34 +280,292 @@ func (h *TMDBHandler) GetMovieReviews(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *TMDBHandler) GetTVReviews(w http.ResponseWriter, r *http.Request) {
-	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	var req idPageRequest
+	if err := decodeRequest(r, &req); err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.client.GetTVReviews(req.ID, req.Page)
+	if err != nil {
+		log.Printf("TMDB TV reviews error: %v", err)
+		writeJSONError(w, cleanTMDBError(err), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+func (h *TMDBHandler) GetMovieRecommendations(w http.ResponseWriter, r *http.Request) {
+	var req idPageRequest
+	if err := decodeRequest(r, &req); err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.client.GetMovieRecommendations(req.ID, req.Page)
 	if err != nil {
-		writeJSONError(w, "invalid tv id", http.StatusBadRequest)
+		log.Printf("TMDB movie recommendations error: %v", err)
+		writeJSONError(w, cleanTMDBError(err), http.StatusBadGateway)
 		return
 	}
-	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
-	if page < 1 {
-		page = 1
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.ratings(r).filterList(result, "movie"))
+}
+
+func (h *TMDBHandler) GetSimilarMovies(w http.ResponseWriter, r *http.Request) {
+	var req idPageRequest
+	if err := decodeRequest(r, &req); err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	result, err := h.client.GetTVReviews(id, page)
+	result, err := h.client.GetSimilarMovies(req.ID, req.Page)
 	if err != nil {
-		log.Printf("TMDB TV reviews error: %v", err)
+		log.Printf("TMDB similar movies error: %v", err)
 		writeJSONError(w, cleanTMDBError(err), http.StatusBadGateway)
 		return
 	}
 
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.ratings(r).filterList(result, "movie"))
+}
+
+func (h *TMDBHandler) GetTVRecommendations(w http.ResponseWriter, r *http.Request) {
+	var req idPageRequest
+	if err := decodeRequest(r, &req); err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.client.GetTVRecommendations(req.ID, req.Page)
+	if err != nil {
+		log.Printf("TMDB TV recommendations error: %v", err)
+		writeJSONError(w, cleanTMDBError(err), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.ratings(r).filterList(result, "tv"))
+}
+
+func (h *TMDBHandler) GetSimilarTV(w http.ResponseWriter, r *http.Request) {
+	var req idPageRequest
+	if err := decodeRequest(r, &req); err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.client.GetSimilarTV(req.ID, req.Page)
+	if err != nil {
+		log.Printf("TMDB similar TV error: %v", err)
+		writeJSONError(w, cleanTMDBError(err), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.ratings(r).filterList(result, "tv"))
+}
+
+func (h *TMDBHandler) GetPerson(w http.ResponseWriter, r *http.Request) {
+	req := personRequest{Sort: tmdb.FilmographySortYear}
+	if err := decodeRequest(r, &req); err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.client.GetPersonDetails(req.ID)
+	if err != nil {
+		log.Printf("TMDB person error: %v", err)
+		writeJSONError(w, cleanTMDBError(err), http.StatusBadGateway)
+		return
+	}
+	h.ratings(r).person(result)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(personResponse{
+		PersonDetails: result,
+		Sort:          req.Sort,
+		Filmography:   result.Filmography(req.Sort),
+	})
+}
+
+type personResponse struct {
+	*tmdb.PersonDetails
+	Sort        string                  `json:"sort"`
+	Filmography []tmdb.FilmographyEntry `json:"filmography"`
+}
+
+func (h *TMDBHandler) GetCollection(w http.ResponseWriter, r *http.Request) {
+	var req idRequest
+	if err := decodeRequest(r, &req); err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.client.GetCollection(req.ID)
+	if err != nil {
+		log.Printf("TMDB collection error: %v", err)
+		writeJSONError(w, cleanTMDBError(err), http.StatusBadGateway)
+		return
+	}
+	result.SortParts()
+	h.ratings(r).collection(result)
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(result)
 }
 
+func (h *TMDBHandler) GetMovieWatchProviders(w http.ResponseWriter, r *http.Request) {
+	var req idRegionRequest
+	if err := decodeRequest(r, &req); err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.client.GetMovieWatchProviders(req.ID)
+	if err != nil {
+		log.Printf("TMDB movie watch providers error: %v", err)
+		writeJSONError(w, cleanTMDBError(err), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(watchProvidersResponse{
+		WatchProvidersResponse: result,
+		Availability:           availability(result, h.prefs.resolve(r)),
+	})
+}
+
+func (h *TMDBHandler) GetTVWatchProviders(w http.ResponseWriter, r *http.Request) {
+	var req idRegionRequest
+	if err := decodeRequest(r, &req); err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.client.GetTVWatchProviders(req.ID)
+	if err != nil {
+		log.Printf("TMDB TV watch providers error: %v", err)
+		writeJSONError(w, cleanTMDBError(err), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(watchProvidersResponse{
+		WatchProvidersResponse: result,
+		Availability:           availability(result, h.prefs.resolve(r)),
+	})
+}
+
+func (h *TMDBHandler) GetMovieVideos(w http.ResponseWriter, r *http.Request) {
+	var req idRequest
+	if err := decodeRequest(r, &req); err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.client.GetMovieVideos(req.ID)
+	if err != nil {
+		log.Printf("TMDB movie videos error: %v", err)
+		writeJSONError(w, cleanTMDBError(err), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tmdb.VideoResponse{ID: result.ID, Results: result.Ranked()})
+}
+
+func (h *TMDBHandler) GetTVVideos(w http.ResponseWriter, r *http.Request) {
+	var req idRequest
+	if err := decodeRequest(r, &req); err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.client.GetTVVideos(req.ID)
+	if err != nil {
+		log.Printf("TMDB TV videos error: %v", err)
+		writeJSONError(w, cleanTMDBError(err), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tmdb.VideoResponse{ID: result.ID, Results: result.Ranked()})
+}
+
+func (h *TMDBHandler) GetMovieAlternativeTitles(w http.ResponseWriter, r *http.Request) {
+	var req idRequest
+	if err := decodeRequest(r, &req); err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	movie, err := h.client.GetMovieTitles(req.ID)
+	if err != nil {
+		log.Printf("TMDB movie alternative titles error: %v", err)
+		writeJSONError(w, cleanTMDBError(err), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(movie.AltTitlesResponse(sceneTitleLimit))
+}
+
+func (h *TMDBHandler) GetTVAlternativeTitles(w http.ResponseWriter, r *http.Request) {
+	var req idRequest
+	if err := decodeRequest(r, &req); err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tv, err := h.client.GetTVTitles(req.ID)
+	if err != nil {
+		log.Printf("TMDB TV alternative titles error: %v", err)
+		writeJSONError(w, cleanTMDBError(err), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tv.AltTitlesResponse(sceneTitleLimit))
+}
+
+func (h *TMDBHandler) GetWatchProviderList(w http.ResponseWriter, r *http.Request) {
+	region := h.prefs.resolve(r).Region
+
+	result, err := h.client.GetWatchProviderList(region)
+	if err != nil {
+		log.Printf("TMDB watch provider list error: %v", err)
+		writeJSONError(w, cleanTMDBError(err), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(providerListResponse{Region: region, Results: result})
+}
+
+func (h *TMDBHandler) GetWatchRegions(w http.ResponseWriter, r *http.Request) {
+	result, err := h.client.GetWatchRegions()
+	if err != nil {
+		log.Printf("TMDB watch regions error: %v", err)
+		writeJSONError(w, cleanTMDBError(err), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(regionsResponse{Results: result})
+}
+
 func (h *TMDBHandler) GetTrendingMovies(w http.ResponseWriter, r *http.Request) {
-	window := r.URL.Query().Get("window")
-	if window == "" {
-		window = "week"
+	req := trendingRequest{Window: "week"}
+	if err := decodeRequest(r, &req); err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	result, err := h.client.GetTrendingMovies(window)
+	result, err := h.client.GetTrendingMovies(req.Window)
 	if err != nil {
 		log.Printf("TMDB trending movies error: %v", err)
 		writeJSONError(w, cleanTMDBError(err), http.StatusBadGateway)
@@ -182,22 +573,208 @@ func (h *TMDBHandler) GetTrendingMovies(w http.ResponseWriter, r *http.Request)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(result)
+	json.NewEncoder(w).Encode(h.ratings(r).filterList(result, "movie"))
 }
 
 func (h *TMDBHandler) GetTrendingTV(w http.ResponseWriter, r *http.Request) {
-	window := r.URL.Query().Get("window")
-	if window == "" {
-		window = "week"
+	req := trendingRequest{Window: "week"}
+	if err := decodeRequest(r, &req); err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	result, err := h.client.GetTrendingTV(window)
+	result, err := h.client.GetTrendingTV(req.Window)
 	if err != nil {
 		log.Printf("TMDB trending TV error: %v", err)
 		writeJSONError(w, cleanTMDBError(err), http.StatusBadGateway)
 		return
 	}
 
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.ratings(r).filterList(result, "tv"))
+}
+
+func (h *TMDBHandler) GetGenres(w http.ResponseWriter, r *http.Request) {
+	req := genresRequest{Language: "en-US"}
+	if err := decodeRequest(r, &req); err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	language := req.Language
+
+	movie, err := h.client.GetGenres("movie", language)
+	if err != nil {
+		log.Printf("TMDB movie genres error: %v", err)
+		writeJSONError(w, cleanTMDBError(err), http.StatusBadGateway)
+		return
+	}
+	tv, err := h.client.GetGenres("tv", language)
+	if err != nil {
+		log.Printf("TMDB TV genres error: %v", err)
+		writeJSONError(w, cleanTMDBError(err), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(genresResponse{Language: language, Movie: movie, TV: tv})
+}
+
+func (h *TMDBHandler) GetGenreTitles(w http.ResponseWriter, r *http.Request) {
+	req := genreTitlesRequest{Type: "movie"}
+	if err := decodeRequest(r, &req); err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.client.Discover(req.Type, req.ID, min(req.Page, discoverMaxPage))
+	if err != nil {
+		log.Printf("TMDB discover error: %v", err)
+		writeJSONError(w, cleanTMDBError(err), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(genreTitlesResponse{
+		ID:           req.ID,
+		Name:         h.client.GenreName(req.Type, req.ID, "en-US"),
+		MediaType:    req.Type,
+		Page:         result.Page,
+		TotalPages:   min(result.TotalPages, discoverMaxPage),
+		TotalResults: result.TotalResults,
+		Results:      h.ratings(r).filter(result.Results, req.Type),
+	})
+}
+
+func (h *TMDBHandler) GetUpcomingMovies(w http.ResponseWriter, r *http.Request) {
+	var req regionPageRequest
+	if err := decodeRequest(r, &req); err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.client.GetUpcomingMovies(h.prefs.resolve(r).Region, req.Page)
+	if err != nil {
+		log.Printf("TMDB upcoming movies error: %v", err)
+		writeJSONError(w, cleanTMDBError(err), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.ratings(r).filterList(result, "movie"))
+}
+
+func (h *TMDBHandler) GetNowPlayingMovies(w http.ResponseWriter, r *http.Request) {
+	var req regionPageRequest
+	if err := decodeRequest(r, &req); err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.client.GetNowPlayingMovies(h.prefs.resolve(r).Region, req.Page)
+	if err != nil {
+		log.Printf("TMDB now playing movies error: %v", err)
+		writeJSONError(w, cleanTMDBError(err), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.ratings(r).filterList(result, "movie"))
+}
+
+func (h *TMDBHandler) GetAiringTodayTV(w http.ResponseWriter, r *http.Request) {
+	var req pageRequest
+	if err := decodeRequest(r, &req); err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.client.GetAiringTodayTV(req.Page)
+	if err != nil {
+		log.Printf("TMDB airing today error: %v", err)
+		writeJSONError(w, cleanTMDBError(err), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.ratings(r).filterList(result, "tv"))
+}
+
+func (h *TMDBHandler) GetOnTheAirTV(w http.ResponseWriter, r *http.Request) {
+	var req pageRequest
+	if err := decodeRequest(r, &req); err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.client.GetOnTheAirTV(req.Page)
+	if err != nil {
+		log.Printf("TMDB on the air error: %v", err)
+		writeJSONError(w, cleanTMDBError(err), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.ratings(r).filterList(result, "tv"))
+}
+
+func (h *TMDBHandler) GetCalendar(w http.ResponseWriter, r *http.Request) {
+	var req calendarRequest
+	if err := decodeRequest(r, &req); err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	region := h.prefs.resolve(r).Region
+	days := calendarDays(req.Days)
+
+	result, err := buildCalendar(h.client, region, days)
+	if err != nil {
+		log.Printf("TMDB calendar error: %v", err)
+		writeJSONError(w, cleanTMDBError(err), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(calendarResponse{Region: region, Days: days, Results: h.ratings(r).calendar(result)})
+}
+
+func (h *TMDBHandler) Find(w http.ResponseWriter, r *http.Request) {
+	req := findRequest{Source: tmdb.SourceIMDb}
+	if err := decodeRequest(r, &req); err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.client.Find(req.ID, req.Source)
+	if err != nil {
+		log.Printf("TMDB find error: %v", err)
+		writeJSONError(w, cleanTMDBError(err), http.StatusBadGateway)
+		return
+	}
+	h.ratings(r).find(result)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+func (h *TMDBHandler) GetTVExternalIDs(w http.ResponseWriter, r *http.Request) {
+	var req idRequest
+	if err := decodeRequest(r, &req); err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !h.ratings(r).allows("tv", req.ID, false) {
+		writeJSONError(w, ratingBlocked, http.StatusForbidden)
+		return
+	}
+
+	result, err := h.client.GetTVExternalIDs(req.ID)
+	if err != nil {
+		log.Printf("TMDB TV external IDs error: %v", err)
+		writeJSONError(w, cleanTMDBError(err), http.StatusBadGateway)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(result)
 }
@@ -0,0 +1,338 @@
+package imagecache
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/unedtamps/orbit/internal/tmdb"
+)
+
+// maxImageBytes bounds a single upstream image download.
+const maxImageBytes = 20 << 20
+
+var (
+	ErrInvalidSize = errors.New("invalid image size")
+	ErrInvalidPath = errors.New("invalid image path")
+	ErrNotFound    = errors.New("image not found")
+)
+
+// tmdbSizes are the sizes image.tmdb.org serves directly.
+var tmdbSizes = map[string]bool{
+	"w45": true, "w92": true, "w154": true, "w185": true, "w200": true,
+	"w300": true, "w342": true, "w500": true, "w780": true, "w1280": true,
+	"h632": true, "original": true,
+}
+
+// tmdbWidths are the w-prefixed TMDB sizes, used as resize sources.
+var tmdbWidths = []int{45, 92, 154, 185, 200, 300, 342, 500, 780, 1280}
+
+// ResponsiveWidths are the extra widths produced by local resizing.
+var ResponsiveWidths = []int{160, 240, 320, 480, 640, 960, 1440}
+
+var filePattern = regexp.MustCompile(`^/[A-Za-z0-9_-]+\.(jpg|jpeg|png|svg)$`)
+
+// Image is a cached file ready to be served.
+type Image struct {
+	Path    string
+	ETag    string
+	ModTime time.Time
+}
+
+type entry struct {
+	size     int64
+	lastUsed time.Time
+}
+
+type call struct {
+	done chan struct{}
+	err  error
+}
+
+// Cache stores TMDB images on disk. Files are fetched once, shared between
+// concurrent requests, and evicted least recently used first once the cache
+// grows beyond maxBytes.
+type Cache struct {
+	dir      string
+	maxBytes int64
+	resize   bool
+	http     *http.Client
+
+	mu       sync.Mutex
+	entries  map[string]*entry
+	total    int64
+	inflight map[string]*call
+}
+
+func New(dir string, maxBytes int64, resize bool) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create image cache dir: %w", err)
+	}
+	c := &Cache{
+		dir:      dir,
+		maxBytes: maxBytes,
+		resize:   resize,
+		http:     &http.Client{Timeout: 30 * time.Second},
+		entries:  make(map[string]*entry),
+		inflight: make(map[string]*call),
+	}
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// load indexes files left by a previous run, using their modification
+// time as the last access.
+func (c *Cache) load() error {
+	err := filepath.WalkDir(c.dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() || strings.HasPrefix(d.Name(), ".tmp-") {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(c.dir, path)
+		if err != nil {
+			return err
+		}
+		c.entries[filepath.ToSlash(rel)] = &entry{size: info.Size(), lastUsed: info.ModTime()}
+		c.total += info.Size()
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to index image cache: %w", err)
+	}
+	c.mu.Lock()
+	c.evict()
+	c.mu.Unlock()
+	return nil
+}
+
+// Get returns the cached image for a TMDB size and file path such as
+// "/abc.jpg", downloading or resizing it first when needed.
+func (c *Cache) Get(ctx context.Context, size, path string) (*Image, error) {
+	if !filePattern.MatchString(path) {
+		return nil, ErrInvalidPath
+	}
+	width, needsResize := 0, false
+	if !tmdbSizes[size] {
+		width = c.responsiveWidth(size)
+		if width == 0 {
+			return nil, ErrInvalidSize
+		}
+		needsResize = true
+	}
+
+	key := size + path
+	for {
+		c.mu.Lock()
+		if e, ok := c.entries[key]; ok {
+			e.lastUsed = time.Now()
+			c.mu.Unlock()
+			return c.image(key)
+		}
+		if cl, ok := c.inflight[key]; ok {
+			c.mu.Unlock()
+			select {
+			case <-cl.done:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			if cl.err != nil {
+				return nil, cl.err
+			}
+			continue
+		}
+		cl := &call{done: make(chan struct{})}
+		c.inflight[key] = cl
+		c.mu.Unlock()
+
+		// The download outlives a cancelled request so waiting requests
+		// still get the file.
+		fetchCtx := context.WithoutCancel(ctx)
+		if needsResize {
+			cl.err = c.storeResized(fetchCtx, key, width, path)
+		} else {
+			cl.err = c.storeUpstream(fetchCtx, key, size, path)
+		}
+
+		c.mu.Lock()
+		delete(c.inflight, key)
+		c.mu.Unlock()
+		close(cl.done)
+
+		if cl.err != nil {
+			return nil, cl.err
+		}
+		return c.image(key)
+	}
+}
+
+func (c *Cache) responsiveWidth(size string) int {
+	if !c.resize || !strings.HasPrefix(size, "w") {
+		return 0
+	}
+	n, err := strconv.Atoi(size[1:])
+	if err != nil {
+		return 0
+	}
+	for _, w := range ResponsiveWidths {
+		if w == n {
+			return n
+		}
+	}
+	return 0
+}
+
+func (c *Cache) image(key string) (*Image, error) {
+	path := filepath.Join(c.dir, filepath.FromSlash(key))
+	info, err := os.Stat(path)
+	if err != nil {
+		c.mu.Lock()
+		c.drop(key)
+		c.mu.Unlock()
+		return nil, ErrNotFound
+	}
+	// TMDB file names are content hashes, so the key identifies the bytes.
+	sum := sha1.Sum([]byte(key))
+	return &Image{
+		Path:    path,
+		ETag:    `"` + hex.EncodeToString(sum[:8]) + `"`,
+		ModTime: info.ModTime(),
+	}, nil
+}
+
+func (c *Cache) storeUpstream(ctx context.Context, key, size, path string) error {
+	data, err := c.fetch(ctx, size, path)
+	if err != nil {
+		return err
+	}
+	return c.write(key, data)
+}
+
+// storeResized downsizes the smallest TMDB width at least as large as the
+// target, falling back to the original.
+func (c *Cache) storeResized(ctx context.Context, key string, width int, path string) error {
+	source := "original"
+	for _, w := range tmdbWidths {
+		if w >= width {
+			source = "w" + strconv.Itoa(w)
+			break
+		}
+	}
+	data, err := c.fetch(ctx, source, path)
+	if err != nil {
+		return err
+	}
+	if !strings.HasSuffix(path, ".svg") {
+		data, err = resize(data, width)
+		if err != nil {
+			return err
+		}
+	}
+	return c.write(key, data)
+}
+
+func (c *Cache) fetch(ctx context.Context, size, path string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tmdb.ImageBaseURL+"/"+size+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("image request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("image CDN error (status %d)", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+	if len(data) > maxImageBytes {
+		return nil, fmt.Errorf("image exceeds %d bytes", maxImageBytes)
+	}
+	return data, nil
+}
+
+// write stores data atomically and evicts old files if the cache is full.
+func (c *Cache) write(key string, data []byte) error {
+	path := filepath.Join(c.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create image dir: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create image file: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write image: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write image: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to store image: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.drop(key)
+	c.entries[key] = &entry{size: int64(len(data)), lastUsed: time.Now()}
+	c.total += int64(len(data))
+	c.evict()
+	return nil
+}
+
+// evict removes least recently used files until the cache fits maxBytes.
+// The caller must hold c.mu.
+func (c *Cache) evict() {
+	if c.maxBytes <= 0 || c.total <= c.maxBytes {
+		return
+	}
+	keys := make([]string, 0, len(c.entries))
+	for k := range c.entries {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return c.entries[keys[i]].lastUsed.Before(c.entries[keys[j]].lastUsed)
+	})
+	for _, k := range keys {
+		if c.total <= c.maxBytes {
+			break
+		}
+		os.Remove(filepath.Join(c.dir, filepath.FromSlash(k)))
+		c.drop(k)
+	}
+}
+
+// drop forgets key. The caller must hold c.mu.
+func (c *Cache) drop(key string) {
+	if e, ok := c.entries[key]; ok {
+		c.total -= e.size
+		delete(c.entries, key)
+	}
+}
@@ -0,0 +1,49 @@
+package imagecache
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/unedtamps/orbit/internal/tmdb"
+)
+
+// URLs builds image URLs for templates. With Proxy set they point at the
+// local /img route, otherwise straight at the TMDB CDN.
+type URLs struct {
+	Proxy  bool
+	Resize bool
+}
+
+func (u URLs) Base() string {
+	if u.Proxy {
+		return "/img"
+	}
+	return tmdb.ImageBaseURL
+}
+
+func (u URLs) URL(size, path string) string {
+	if path == "" {
+		return ""
+	}
+	return u.Base() + "/" + size + path
+}
+
+// Srcset lists every available width up to maxWidth. Locally resized
+// widths are offered only when both the proxy and resizing are enabled.
+func (u URLs) Srcset(path string, maxWidth int) string {
+	if path == "" {
+		return ""
+	}
+	widths := tmdbWidths
+	if u.Proxy && u.Resize {
+		widths = ResponsiveWidths
+	}
+	var parts []string
+	for _, w := range widths {
+		if w < 150 || w > maxWidth {
+			continue
+		}
+		parts = append(parts, u.URL("w"+strconv.Itoa(w), path)+" "+strconv.Itoa(w)+"w")
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,182 @@
+package imdb
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The dumps published at https://datasets.imdbws.com/. Each may be kept
+// gzipped or unpacked.
+const (
+	BasicsFile   = "title.basics.tsv"
+	RatingsFile  = "title.ratings.tsv"
+	EpisodesFile = "title.episode.tsv"
+)
+
+// Import builds a store from the dumps in dir. progress, if set, is called
+// after each file with its name and row count.
+func Import(dir string, progress func(file string, rows int)) (*Store, error) {
+	s := &Store{data: storeData{Version: formatVersion, Imported: time.Now().UTC()}}
+	d := &s.data
+
+	steps := []struct {
+		file string
+		row  func(cols []string)
+	}{
+		{BasicsFile, func(cols []string) {
+			id, ok := ParseID(cols[0])
+			if !ok || len(cols) < 6 {
+				return
+			}
+			d.TitleIDs = append(d.TitleIDs, id)
+			d.TitleKinds = append(d.TitleKinds, kindIndex(cols[1]))
+			d.TitleYears = append(d.TitleYears, small(cols[5]))
+		}},
+		{RatingsFile, func(cols []string) {
+			id, ok := ParseID(cols[0])
+			if !ok || len(cols) < 3 {
+				return
+			}
+			avg, _ := strconv.ParseFloat(cols[1], 64)
+			d.RatingIDs = append(d.RatingIDs, id)
+			d.RatingTenth = append(d.RatingTenth, uint8(avg*10+0.5))
+			d.RatingVotes = append(d.RatingVotes, uint32(atoi(cols[2])))
+		}},
+		{EpisodesFile, func(cols []string) {
+			if len(cols) < 4 || cols[2] == `\N` || cols[3] == `\N` {
+				return
+			}
+			id, ok := ParseID(cols[0])
+			parent, pok := ParseID(cols[1])
+			if !ok || !pok {
+				return
+			}
+			d.EpisodeIDs = append(d.EpisodeIDs, id)
+			d.EpisodeParents = append(d.EpisodeParents, parent)
+			d.EpisodeSeasons = append(d.EpisodeSeasons, small(cols[2]))
+			d.EpisodeNumbers = append(d.EpisodeNumbers, small(cols[3]))
+		}},
+	}
+
+	for _, step := range steps {
+		rows, err := readTSV(dir, step.file, step.row)
+		if err != nil {
+			return nil, err
+		}
+		if progress != nil {
+			progress(step.file, rows)
+		}
+	}
+
+	sort.Sort(titleOrder{d})
+	sort.Sort(ratingOrder{d})
+	sort.Sort(episodeOrder{d})
+	return s, nil
+}
+
+// readTSV streams a dump, skipping its header row.
+func readTSV(dir, name string, row func(cols []string)) (int, error) {
+	f, err := os.Open(filepath.Join(dir, name+".gz"))
+	if errors.Is(err, os.ErrNotExist) {
+		f, err = os.Open(filepath.Join(dir, name))
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to open %s: %w", name, err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(f.Name(), ".gz") {
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read %s: %w", f.Name(), err)
+		}
+		defer zr.Close()
+		r = zr
+	}
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1<<20)
+	rows := 0
+	for sc.Scan() {
+		if rows++; rows == 1 {
+			continue
+		}
+		row(strings.Split(sc.Text(), "\t"))
+	}
+	if err := sc.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read %s: %w", f.Name(), err)
+	}
+	return rows - 1, nil
+}
+
+// atoi treats IMDb's \N placeholder, and anything unparseable, as zero.
+func atoi(s string) int {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
+// small narrows years, seasons and episode numbers, dropping the rare
+// bogus value that would not fit.
+func small(s string) uint16 {
+	n := atoi(s)
+	if n > 0xffff {
+		return 0
+	}
+	return uint16(n)
+}
+
+type titleOrder struct{ d *storeData }
+
+func (o titleOrder) Len() int           { return len(o.d.TitleIDs) }
+func (o titleOrder) Less(i, j int) bool { return o.d.TitleIDs[i] < o.d.TitleIDs[j] }
+func (o titleOrder) Swap(i, j int) {
+	d := o.d
+	d.TitleIDs[i], d.TitleIDs[j] = d.TitleIDs[j], d.TitleIDs[i]
+	d.TitleKinds[i], d.TitleKinds[j] = d.TitleKinds[j], d.TitleKinds[i]
+	d.TitleYears[i], d.TitleYears[j] = d.TitleYears[j], d.TitleYears[i]
+}
+
+type ratingOrder struct{ d *storeData }
+
+func (o ratingOrder) Len() int           { return len(o.d.RatingIDs) }
+func (o ratingOrder) Less(i, j int) bool { return o.d.RatingIDs[i] < o.d.RatingIDs[j] }
+func (o ratingOrder) Swap(i, j int) {
+	d := o.d
+	d.RatingIDs[i], d.RatingIDs[j] = d.RatingIDs[j], d.RatingIDs[i]
+	d.RatingTenth[i], d.RatingTenth[j] = d.RatingTenth[j], d.RatingTenth[i]
+	d.RatingVotes[i], d.RatingVotes[j] = d.RatingVotes[j], d.RatingVotes[i]
+}
+
+type episodeOrder struct{ d *storeData }
+
+func (o episodeOrder) Len() int { return len(o.d.EpisodeIDs) }
+func (o episodeOrder) Less(i, j int) bool {
+	d := o.d
+	if d.EpisodeParents[i] != d.EpisodeParents[j] {
+		return d.EpisodeParents[i] < d.EpisodeParents[j]
+	}
+	if d.EpisodeSeasons[i] != d.EpisodeSeasons[j] {
+		return d.EpisodeSeasons[i] < d.EpisodeSeasons[j]
+	}
+	return d.EpisodeNumbers[i] < d.EpisodeNumbers[j]
+}
+func (o episodeOrder) Swap(i, j int) {
+	d := o.d
+	d.EpisodeIDs[i], d.EpisodeIDs[j] = d.EpisodeIDs[j], d.EpisodeIDs[i]
+	d.EpisodeParents[i], d.EpisodeParents[j] = d.EpisodeParents[j], d.EpisodeParents[i]
+	d.EpisodeSeasons[i], d.EpisodeSeasons[j] = d.EpisodeSeasons[j], d.EpisodeSeasons[i]
+	d.EpisodeNumbers[i], d.EpisodeNumbers[j] = d.EpisodeNumbers[j], d.EpisodeNumbers[i]
+}
@@ -0,0 +1,279 @@
+// Package imdb answers rating, episode and ID questions from the public
+// IMDb TSV dumps, imported ahead of time so requests never leave the
+// server.
+package imdb
+
+import (
+	"compress/gzip"
+	"encoding/gob"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// formatVersion is bumped whenever storeData changes shape.
+const formatVersion = 1
+
+// Kinds are IMDb titleType values, stored by index.
+var kinds = []string{
+	"", "movie", "short", "tvSeries", "tvEpisode", "tvMovie",
+	"tvMiniSeries", "tvSpecial", "tvShort", "video", "videoGame", "tvPilot",
+}
+
+func kindIndex(s string) uint8 {
+	for i, k := range kinds {
+		if k == s {
+			return uint8(i)
+		}
+	}
+	return 0
+}
+
+// Title is the little the store keeps from title.basics.
+type Title struct {
+	ID   string `json:"id"`
+	Kind string `json:"kind"`
+	Year int    `json:"year,omitempty"`
+}
+
+// IsSeries reports whether the title is a show rather than a film or an
+// episode.
+func (t Title) IsSeries() bool {
+	return t.Kind == "tvSeries" || t.Kind == "tvMiniSeries"
+}
+
+// IsMovie reports whether the title is something TMDB files as a movie.
+func (t Title) IsMovie() bool {
+	switch t.Kind {
+	case "movie", "tvMovie", "short", "video", "tvSpecial":
+		return true
+	}
+	return false
+}
+
+type Rating struct {
+	Average float64 `json:"average"`
+	Votes   int     `json:"votes"`
+}
+
+// VotesLabel shortens vote counts the way IMDb does: 950, 12K, 1.3M.
+func (r Rating) VotesLabel() string {
+	switch {
+	case r.Votes >= 1_000_000:
+		return strconv.FormatFloat(float64(r.Votes)/1_000_000, 'f', 1, 64) + "M"
+	case r.Votes >= 1_000:
+		return strconv.Itoa(r.Votes/1_000) + "K"
+	default:
+		return strconv.Itoa(r.Votes)
+	}
+}
+
+// Episode is an IMDb episode placed in its show's numbering.
+type Episode struct {
+	ID      string  `json:"imdb_id"`
+	Season  int     `json:"season"`
+	Episode int     `json:"episode"`
+	Rating  *Rating `json:"rating,omitempty"`
+}
+
+// storeData is the on-disk form. Every group of slices is parallel and
+// sorted by its first column, so lookups are binary searches and the
+// whole dump fits in memory.
+type storeData struct {
+	Version  int
+	Imported time.Time
+
+	TitleIDs   []uint32
+	TitleKinds []uint8
+	TitleYears []uint16
+
+	RatingIDs   []uint32
+	RatingTenth []uint8
+	RatingVotes []uint32
+
+	// Episodes are sorted by parent, season, then episode number.
+	EpisodeParents []uint32
+	EpisodeSeasons []uint16
+	EpisodeNumbers []uint16
+	EpisodeIDs     []uint32
+}
+
+// Store is safe for concurrent reads. A nil *Store answers every lookup
+// with nothing, so callers need not check whether a dataset is loaded.
+type Store struct {
+	data storeData
+}
+
+// ParseID converts "tt0000001" to 1.
+func ParseID(id string) (uint32, bool) {
+	if len(id) < 3 || id[:2] != "tt" {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(id[2:], 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return uint32(n), true
+}
+
+// FormatID converts 1 to "tt0000001", zero-padded to at least seven digits.
+func FormatID(n uint32) string {
+	return fmt.Sprintf("tt%07d", n)
+}
+
+func search(ids []uint32, id uint32) (int, bool) {
+	i := sort.Search(len(ids), func(i int) bool { return ids[i] >= id })
+	return i, i < len(ids) && ids[i] == id
+}
+
+// Title looks up a title by IMDb ID.
+func (s *Store) Title(id string) (Title, bool) {
+	n, ok := ParseID(id)
+	if s == nil || !ok {
+		return Title{}, false
+	}
+	i, ok := search(s.data.TitleIDs, n)
+	if !ok {
+		return Title{}, false
+	}
+	t := Title{ID: FormatID(n), Year: int(s.data.TitleYears[i])}
+	if k := int(s.data.TitleKinds[i]); k < len(kinds) {
+		t.Kind = kinds[k]
+	}
+	return t, true
+}
+
+// Rating returns the IMDb rating of a title, or nil if it has none.
+func (s *Store) Rating(id string) *Rating {
+	n, ok := ParseID(id)
+	if s == nil || !ok {
+		return nil
+	}
+	return s.rating(n)
+}
+
+func (s *Store) rating(n uint32) *Rating {
+	i, ok := search(s.data.RatingIDs, n)
+	if !ok {
+		return nil
+	}
+	return &Rating{
+		Average: float64(s.data.RatingTenth[i]) / 10,
+		Votes:   int(s.data.RatingVotes[i]),
+	}
+}
+
+// Season returns the episodes IMDb lists for a show's season, keyed by
+// episode number.
+func (s *Store) Season(seriesID string, season int) map[int]Episode {
+	n, ok := ParseID(seriesID)
+	if s == nil || !ok {
+		return nil
+	}
+	d := &s.data
+	i := sort.Search(len(d.EpisodeParents), func(i int) bool {
+		if d.EpisodeParents[i] != n {
+			return d.EpisodeParents[i] > n
+		}
+		return int(d.EpisodeSeasons[i]) >= season
+	})
+
+	var out map[int]Episode
+	for ; i < len(d.EpisodeParents) && d.EpisodeParents[i] == n && int(d.EpisodeSeasons[i]) == season; i++ {
+		if out == nil {
+			out = make(map[int]Episode)
+		}
+		num := int(d.EpisodeNumbers[i])
+		out[num] = Episode{
+			ID:      FormatID(d.EpisodeIDs[i]),
+			Season:  season,
+			Episode: num,
+			Rating:  s.rating(d.EpisodeIDs[i]),
+		}
+	}
+	return out
+}
+
+// Valid reports whether id is a known IMDb title that passes check. When
+// no dataset is loaded every well-formed ID is accepted.
+func (s *Store) Valid(id string, check func(Title) bool) bool {
+	if _, ok := ParseID(id); !ok {
+		return false
+	}
+	if s == nil {
+		return true
+	}
+	t, ok := s.Title(id)
+	return ok && (check == nil || check(t))
+}
+
+// Imported is when the dataset was built, or the zero time when none is
+// loaded.
+func (s *Store) Imported() time.Time {
+	if s == nil {
+		return time.Time{}
+	}
+	return s.data.Imported
+}
+
+// Open loads a store written by Save.
+func Open(path string) (*Store, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	defer zr.Close()
+
+	s := &Store{}
+	if err := gob.NewDecoder(zr).Decode(&s.data); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	if s.data.Version != formatVersion {
+		return nil, fmt.Errorf("%s has format version %d, want %d; re-run the import", path, s.data.Version, formatVersion)
+	}
+	return s, nil
+}
+
+// Save writes the store atomically, so a running server never opens a
+// half-written file.
+func (s *Store) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".imdb-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	zw := gzip.NewWriter(tmp)
+	if err := gob.NewEncoder(zw).Encode(&s.data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Counts reports how many titles, ratings and episodes the store holds.
+func (s *Store) Counts() (titles, ratings, episodes int) {
+	if s == nil {
+		return 0, 0, 0
+	}
+	return len(s.data.TitleIDs), len(s.data.RatingIDs), len(s.data.EpisodeIDs)
+}
@@ -0,0 +1,142 @@
+// Package lookup turns links and IDs from other movie sites into a TMDB
+// page or an external ID that TMDB's find endpoint can resolve.
+package lookup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/unedtamps/orbit/internal/tmdb"
+)
+
+// Target is where a link points. Either MediaType and TMDBID are set, or
+// ExternalID and Source are, for resolution through tmdb.Client.Find.
+type Target struct {
+	MediaType  string
+	TMDBID     string
+	ExternalID string
+	Source     string
+}
+
+// Path returns the Orbit page for a TMDB target.
+func (t Target) Path() string {
+	return "/" + t.MediaType + "/" + t.TMDBID
+}
+
+var (
+	ErrUnsupported = errors.New("unsupported link")
+	ErrNotFound    = errors.New("no TMDB or IMDb reference found")
+
+	imdbID       = regexp.MustCompile(`^(tt|nm)\d{5,}$`)
+	imdbPath     = regexp.MustCompile(`^/(?:[a-z]{2}/)?(?:title|name)/((?:tt|nm)\d{5,})`)
+	tmdbPath     = regexp.MustCompile(`^/(movie|tv|person|collection)/(\d+)`)
+	pageTMDBLink = regexp.MustCompile(`themoviedb\.org/(movie|tv)/(\d+)`)
+	pageTMDBData = regexp.MustCompile(`data-tmdb-id="(\d+)"`)
+	pageTMDBType = regexp.MustCompile(`data-tmdb-type="(movie|tv)"`)
+	pageIMDbLink = regexp.MustCompile(`imdb\.com/title/(tt\d{5,})`)
+)
+
+// maxPageBytes caps how much of a scraped page is read.
+const maxPageBytes = 2 << 20
+
+type Resolver struct {
+	http *http.Client
+}
+
+func NewResolver() *Resolver {
+	return &Resolver{http: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// IsIMDbID reports whether s is an IMDb title or name ID.
+func IsIMDbID(s string) bool {
+	return imdbID.MatchString(s)
+}
+
+// IsLink reports whether a search query is a bare IMDb ID or a URL, and
+// so should be resolved rather than searched for.
+func IsLink(s string) bool {
+	s = strings.TrimSpace(s)
+	return imdbID.MatchString(s) || strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
+
+// Resolve accepts a bare IMDb ID or an IMDb, TMDB, Letterboxd or Trakt
+// URL. IMDb and TMDB links are parsed directly; Letterboxd and Trakt
+// pages carry no ID in the URL, so the page is fetched and scanned for
+// its TMDB or IMDb link.
+func (r *Resolver) Resolve(ctx context.Context, raw string) (Target, error) {
+	raw = strings.TrimSpace(raw)
+	if imdbID.MatchString(raw) {
+		return Target{ExternalID: raw, Source: tmdb.SourceIMDb}, nil
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return Target{}, ErrUnsupported
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	switch {
+	case host == "imdb.com" || strings.HasSuffix(host, ".imdb.com"):
+		if m := imdbPath.FindStringSubmatch(u.Path); m != nil {
+			return Target{ExternalID: m[1], Source: tmdb.SourceIMDb}, nil
+		}
+	case host == "themoviedb.org":
+		if m := tmdbPath.FindStringSubmatch(u.Path); m != nil {
+			return Target{MediaType: m[1], TMDBID: m[2]}, nil
+		}
+	case host == "letterboxd.com" || host == "boxd.it",
+		host == "trakt.tv" || host == "app.trakt.tv":
+		return r.scrape(ctx, u.String())
+	}
+	return Target{}, ErrUnsupported
+}
+
+func (r *Resolver) scrape(ctx context.Context, pageURL string) (Target, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return Target{}, err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; OrbitSearch)")
+
+	resp, err := r.http.Do(req)
+	if err != nil {
+		return Target{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Target{}, fmt.Errorf("fetching %s: status %d", pageURL, resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxPageBytes))
+	if err != nil {
+		return Target{}, err
+	}
+	return fromPage(string(body))
+}
+
+// fromPage prefers Letterboxd's data-tmdb attributes, then any TMDB link,
+// then an IMDb title link.
+func fromPage(page string) (Target, error) {
+	if m := pageTMDBData.FindStringSubmatch(page); m != nil {
+		mediaType := "movie"
+		if t := pageTMDBType.FindStringSubmatch(page); t != nil {
+			mediaType = t[1]
+		}
+		return Target{MediaType: mediaType, TMDBID: m[1]}, nil
+	}
+	if m := pageTMDBLink.FindStringSubmatch(page); m != nil {
+		return Target{MediaType: m[1], TMDBID: m[2]}, nil
+	}
+	if m := pageIMDbLink.FindStringSubmatch(page); m != nil {
+		return Target{ExternalID: m[1], Source: tmdb.SourceIMDb}, nil
+	}
+	return Target{}, ErrNotFound
+}
@@ -0,0 +1,148 @@
+package metadata
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/unedtamps/orbit/internal/tmdb"
+)
+
+// Chain asks providers in order and returns the first answer, then lets
+// the remaining providers fill missing fields and record the result.
+type Chain struct {
+	providers []MetadataProvider
+}
+
+func NewChain(providers ...MetadataProvider) *Chain {
+	return &Chain{providers: providers}
+}
+
+// Names lists the providers in the order they are asked.
+func (c *Chain) Names() []string {
+	names := make([]string, len(c.providers))
+	for i, p := range c.providers {
+		names[i] = p.Name()
+	}
+	return names
+}
+
+// first runs fetch against each provider until one succeeds, and returns
+// that provider. The error joins every provider's failure.
+func (c *Chain) first(fetch func(MetadataProvider) error) (MetadataProvider, error) {
+	var errs []string
+	for _, p := range c.providers {
+		err := fetch(p)
+		if err == nil {
+			return p, nil
+		}
+		if !errors.Is(err, ErrUnsupported) && !errors.Is(err, ErrNotFound) {
+			log.Printf("Metadata provider %s failed: %v", p.Name(), err)
+		}
+		errs = append(errs, p.Name()+": "+err.Error())
+	}
+	if len(errs) == 0 {
+		return nil, errors.New("no metadata providers configured")
+	}
+	return nil, fmt.Errorf("all metadata providers failed (%s)", strings.Join(errs, "; "))
+}
+
+// record hands v to every recorder other than the provider that served it.
+func (c *Chain) record(source MetadataProvider, kind, key string, v interface{}) {
+	for _, p := range c.providers {
+		if p == source {
+			continue
+		}
+		if r, ok := p.(Recorder); ok {
+			if err := r.Record(kind, key, v); err != nil {
+				log.Printf("Metadata %s record error: %v", p.Name(), err)
+			}
+		}
+	}
+}
+
+// fillers returns every Filler other than source.
+func (c *Chain) fillers(source MetadataProvider) []MetadataProvider {
+	var out []MetadataProvider
+	for _, p := range c.providers {
+		if _, ok := p.(Filler); ok && p != source {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func (c *Chain) MultiSearch(query string, page int) (*tmdb.MultiSearchResponse, *Provenance, error) {
+	var result *tmdb.MultiSearchResponse
+	source, err := c.first(func(p MetadataProvider) (err error) {
+		result, err = p.MultiSearch(query, page)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return result, &Provenance{Source: source.Name()}, nil
+}
+
+func (c *Chain) GetMovieDetails(id int) (*tmdb.MovieDetails, *Provenance, error) {
+	var result *tmdb.MovieDetails
+	source, err := c.first(func(p MetadataProvider) (err error) {
+		result, err = p.GetMovieDetails(id)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	c.record(source, "movie", strconv.Itoa(id), result)
+	return result, &Provenance{Source: source.Name()}, nil
+}
+
+func (c *Chain) GetTVDetails(id int) (*tmdb.TVDetails, *Provenance, error) {
+	var result *tmdb.TVDetails
+	source, err := c.first(func(p MetadataProvider) (err error) {
+		result, err = p.GetTVDetails(id)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Recorded before filling so a snapshot never passes filled fields
+	// off as the source's own.
+	c.record(source, "tv", strconv.Itoa(id), result)
+
+	prov := &Provenance{Source: source.Name()}
+	for _, p := range c.fillers(source) {
+		fields, err := p.(Filler).FillTVDetails(result)
+		if err != nil {
+			log.Printf("Metadata %s fill error for TV %d: %v", p.Name(), id, err)
+		}
+		prov.add(p.Name(), fields)
+	}
+	return result, prov, nil
+}
+
+func (c *Chain) GetSeasonDetails(tvID int, seasonNumber int) (*tmdb.SeasonDetails, *Provenance, error) {
+	var result *tmdb.SeasonDetails
+	source, err := c.first(func(p MetadataProvider) (err error) {
+		result, err = p.GetSeasonDetails(tvID, seasonNumber)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	c.record(source, "season", fmt.Sprintf("%d-%d", tvID, seasonNumber), result)
+
+	prov := &Provenance{Source: source.Name()}
+	for _, p := range c.fillers(source) {
+		fields, err := p.(Filler).FillSeasonDetails(tvID, result)
+		if err != nil {
+			log.Printf("Metadata %s fill error for TV %d season %d: %v", p.Name(), tvID, seasonNumber, err)
+		}
+		prov.add(p.Name(), fields)
+	}
+	return result, prov, nil
+}
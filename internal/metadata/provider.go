@@ -0,0 +1,103 @@
+// Package metadata puts TMDB behind an interface so other sources can fill
+// gaps in its data or answer when it is unavailable.
+package metadata
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/unedtamps/orbit/internal/tmdb"
+)
+
+// ErrUnsupported is returned by providers for lookups they cannot answer.
+var ErrUnsupported = errors.New("not supported by this provider")
+
+// MetadataProvider answers title lookups keyed by TMDB ID, which stays
+// Orbit's canonical ID whichever provider serves the data.
+type MetadataProvider interface {
+	Name() string
+	MultiSearch(query string, page int) (*tmdb.MultiSearchResponse, error)
+	GetMovieDetails(id int) (*tmdb.MovieDetails, error)
+	GetTVDetails(id int) (*tmdb.TVDetails, error)
+	GetSeasonDetails(tvID int, seasonNumber int) (*tmdb.SeasonDetails, error)
+}
+
+var _ MetadataProvider = (*tmdb.Client)(nil)
+
+// Filler is implemented by providers that can complete records fetched
+// from another provider. Each method returns the JSON paths it filled.
+type Filler interface {
+	FillTVDetails(tv *tmdb.TVDetails) ([]string, error)
+	FillSeasonDetails(tvID int, season *tmdb.SeasonDetails) ([]string, error)
+}
+
+// Recorder is implemented by providers that keep copies of records served
+// by other providers, such as Snapshot. Record must copy v before it
+// returns; the chain goes on to fill it in.
+type Recorder interface {
+	Record(kind, key string, v interface{}) error
+}
+
+// Provenance says which provider served a record and which fields other
+// providers filled in.
+type Provenance struct {
+	Source string            `json:"source"`
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+func (p *Provenance) add(provider string, fields []string) {
+	if len(fields) == 0 {
+		return
+	}
+	if p.Fields == nil {
+		p.Fields = make(map[string]string)
+	}
+	for _, f := range fields {
+		p.Fields[f] = provider
+	}
+}
+
+// Fallback reports whether the record came from a provider other than the
+// primary one, for pages that flag possibly stale data.
+func (p *Provenance) Fallback() bool {
+	return p != nil && p.Source != "tmdb"
+}
+
+var labels = map[string]string{
+	"tmdb":     "TMDB",
+	"tvmaze":   "TVmaze",
+	"snapshot": "a saved copy",
+}
+
+func label(name string) string {
+	if l, ok := labels[name]; ok {
+		return l
+	}
+	return name
+}
+
+// Notice is the line pages show when data did not all come from TMDB, or
+// "" when it did.
+func (p *Provenance) Notice() string {
+	if p == nil {
+		return ""
+	}
+	if p.Fallback() {
+		return "TMDB is unavailable, so this page uses " + label(p.Source) + " and may be incomplete or out of date."
+	}
+
+	seen := make(map[string]bool)
+	var fillers []string
+	for _, provider := range p.Fields {
+		if !seen[provider] {
+			seen[provider] = true
+			fillers = append(fillers, label(provider))
+		}
+	}
+	if len(fillers) == 0 {
+		return ""
+	}
+	sort.Strings(fillers)
+	return "Some details were filled in from " + strings.Join(fillers, " and ") + "."
+}
@@ -0,0 +1,183 @@
+package metadata
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/unedtamps/orbit/internal/tmdb"
+)
+
+const (
+	// snapshotRefresh is how long a record is kept before a view
+	// rewrites it, so busy pages do not write on every request.
+	snapshotRefresh = time.Hour
+	// snapshotMaxAge is how long a record not viewed since is kept.
+	snapshotMaxAge = 30 * 24 * time.Hour
+	// snapshotPruneInterval is how often expired records are removed.
+	snapshotPruneInterval = time.Hour
+)
+
+// ErrNotFound is returned by Snapshot for records it has never seen, or
+// has not seen within snapshotMaxAge.
+var ErrNotFound = errors.New("not in snapshot")
+
+// Snapshot is an offline provider backed by the last good copy of every
+// record the chain served, one JSON file per record under dir.
+type Snapshot struct {
+	dir string
+
+	mu     sync.Mutex
+	pruned time.Time
+}
+
+func NewSnapshot(dir string) (*Snapshot, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	s := &Snapshot{dir: dir, pruned: time.Now()}
+	s.prune()
+	return s, nil
+}
+
+func (s *Snapshot) Name() string { return "snapshot" }
+
+func (s *Snapshot) path(kind, key string) string {
+	return filepath.Join(s.dir, kind, key+".json")
+}
+
+// Record writes v atomically so a crash never leaves a torn record behind.
+// Records written within snapshotRefresh are left as they are.
+func (s *Snapshot) Record(kind, key string, v interface{}) error {
+	s.maybePrune()
+
+	path := s.path(kind, key)
+	if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) < snapshotRefresh {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *Snapshot) load(kind, key string, v interface{}) error {
+	path := s.path(kind, key)
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) || err == nil && time.Since(info.ModTime()) >= snapshotMaxAge {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// maybePrune starts a prune in the background at most once per
+// snapshotPruneInterval.
+func (s *Snapshot) maybePrune() {
+	s.mu.Lock()
+	due := time.Since(s.pruned) >= snapshotPruneInterval
+	if due {
+		s.pruned = time.Now()
+	}
+	s.mu.Unlock()
+	if due {
+		go s.prune()
+	}
+}
+
+// prune removes records older than snapshotMaxAge, and temporary files
+// left by a crash.
+func (s *Snapshot) prune() {
+	err := filepath.WalkDir(s.dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		maxAge := snapshotMaxAge
+		if strings.HasPrefix(d.Name(), ".tmp-") {
+			maxAge = snapshotRefresh
+		}
+		if time.Since(info.ModTime()) >= maxAge {
+			os.Remove(path)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Metadata snapshot prune error: %v", err)
+	}
+}
+
+func (s *Snapshot) MultiSearch(query string, page int) (*tmdb.MultiSearchResponse, error) {
+	return nil, ErrUnsupported
+}
+
+func (s *Snapshot) GetMovieDetails(id int) (*tmdb.MovieDetails, error) {
+	var result tmdb.MovieDetails
+	if err := s.load("movie", strconv.Itoa(id), &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (s *Snapshot) GetTVDetails(id int) (*tmdb.TVDetails, error) {
+	var result tmdb.TVDetails
+	if err := s.load("tv", strconv.Itoa(id), &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (s *Snapshot) GetSeasonDetails(tvID int, seasonNumber int) (*tmdb.SeasonDetails, error) {
+	var result tmdb.SeasonDetails
+	if err := s.load("season", fmt.Sprintf("%d-%d", tvID, seasonNumber), &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetTVExternalIDs answers from the stored show so other providers can map
+// IDs while TMDB is down.
+func (s *Snapshot) GetTVExternalIDs(id int) (*tmdb.ExternalIDs, error) {
+	tv, err := s.GetTVDetails(id)
+	if err != nil {
+		return nil, err
+	}
+	if tv.ExternalIDs == nil {
+		return nil, ErrNotFound
+	}
+	return tv.ExternalIDs, nil
+}
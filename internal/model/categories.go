@@ -10,5 +10,9 @@ var TVCategories = []uint{
 	5000, 5050, 5070, 5080, 143862, 105852, 112972, 100205, 100212, 100002,
 }
 
+// Anime categories for Jackett searches: TV/Anime, which anime trackers
+// such as Nyaa map their English-translated and raw sections to.
+var AnimeCategories = []uint{5070}
+
 // AltSearchCategories is used for alternative trackers (e.g. rutor).
 var AltSearchCategories = []uint{8000}
@@ -0,0 +1,100 @@
+package model
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	resolutionRe = regexp.MustCompile(`(?i)\b(2160p|4k|uhd|1080p|720p|576p|480p|sd)\b`)
+	codecRe      = regexp.MustCompile(`(?i)\b(x\.?264|h\.?264|avc|x\.?265|h\.?265|hevc|av1|xvid|divx|vp9)\b`)
+	sourceRe     = regexp.MustCompile(`(?i)\b(remux|blu-?ray|bdrip|brrip|web-?dl|web-?rip|web|hdtv|dvdrip|dvd|hdrip|hdcam|cam|telesync|hdts|ts|screener|scr)\b`)
+)
+
+// Quality is what a release name says about its video.
+type Quality struct {
+	Resolution string `json:"resolution,omitempty"`
+	Codec      string `json:"codec,omitempty"`
+	Source     string `json:"source,omitempty"`
+}
+
+// ParseQuality reads resolution, codec and source from a release name.
+func ParseQuality(title string) Quality {
+	return Quality{
+		Resolution: ParseResolution(title),
+		Codec:      ParseCodec(title),
+		Source:     ParseSource(title),
+	}
+}
+
+// ParseResolution returns a release's resolution, with 4K and UHD read as
+// 2160p and SD as 480p, or "" when the name does not say.
+func ParseResolution(title string) string {
+	m := resolutionRe.FindStringSubmatch(title)
+	if m == nil {
+		return ""
+	}
+	switch r := strings.ToLower(m[1]); r {
+	case "4k", "uhd":
+		return "2160p"
+	case "sd":
+		return "480p"
+	default:
+		return r
+	}
+}
+
+// ParseCodec returns a release's video codec, with H.264/AVC read as
+// x264, H.265/HEVC as x265 and DivX as xvid, or "" when the name does not
+// say.
+func ParseCodec(title string) string {
+	m := codecRe.FindStringSubmatch(title)
+	if m == nil {
+		return ""
+	}
+	switch c := strings.ToLower(strings.ReplaceAll(m[1], ".", "")); c {
+	case "h264", "avc":
+		return "x264"
+	case "h265", "hevc":
+		return "x265"
+	case "divx":
+		return "xvid"
+	default:
+		return c
+	}
+}
+
+// ParseSource returns where a release was ripped from: remux, bluray,
+// web-dl, webrip, hdtv, dvd, cam, telesync or screener, or "" when the
+// name does not say.
+func ParseSource(title string) string {
+	matches := sourceRe.FindAllStringSubmatch(title, -1)
+	if matches == nil {
+		return ""
+	}
+	// A remux names its disc too, as in "BluRay REMUX"; the remux wins.
+	m := matches[0]
+	for _, other := range matches {
+		if strings.EqualFold(other[1], "remux") {
+			m = other
+		}
+	}
+	switch s := strings.ToLower(strings.ReplaceAll(m[1], "-", "")); s {
+	case "bluray", "bdrip", "brrip":
+		return "bluray"
+	case "webdl", "web":
+		return "web-dl"
+	case "dvdrip", "dvd":
+		return "dvd"
+	case "hdrip":
+		return "webrip"
+	case "hdcam":
+		return "cam"
+	case "ts", "hdts":
+		return "telesync"
+	case "scr":
+		return "screener"
+	default:
+		return s
+	}
+}
@@ -0,0 +1,141 @@
+package model
+
+import (
+	"encoding/base32"
+	"encoding/hex"
+	"net/url"
+	"strings"
+	"time"
+
+	jackett "github.com/webtor-io/go-jackett"
+)
+
+// ReleaseVersion is the version of the Release schema, sent with every
+// release. It goes up when a field is removed or changes meaning; added
+// fields do not change it.
+const ReleaseVersion = 1
+
+// Release is a torrent release as Orbit's APIs return it, whichever
+// indexer backend found it.
+type Release struct {
+	Version int    `json:"version"`
+	Title   string `json:"title"`
+	// InfoHash is the lowercase hex BitTorrent v1 hash.
+	InfoHash string `json:"info_hash,omitempty"`
+	Magnet   string `json:"magnet,omitempty"`
+	// DownloadURL is the signed /dl/ proxy URL of the .torrent file, for
+	// releases the indexer only offers as a download.
+	DownloadURL string  `json:"download_url,omitempty"`
+	Quality     Quality `json:"quality"`
+	// Indexer is the tracker site the release was found on; Trackers are
+	// the announce URLs its magnet lists.
+	Indexer     string     `json:"indexer"`
+	Trackers    []string   `json:"trackers,omitempty"`
+	Size        uint64     `json:"size"`
+	Seeders     uint       `json:"seeders"`
+	Leechers    uint       `json:"leechers"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+}
+
+// RawRelease is one result as an indexer backend reports it, before
+// NewRelease normalizes it. Link may be a magnet or a download URL; Peers
+// counts seeders too.
+type RawRelease struct {
+	Indexer     string
+	Title       string
+	Link        string
+	MagnetURI   string
+	InfoHash    string
+	Size        uint64
+	Seeders     uint
+	Peers       uint
+	PublishDate time.Time
+}
+
+// NewRelease normalizes a backend result: the hash is read from the
+// magnet when the backend leaves it out, and a magnet is built from the
+// hash when the backend only has that.
+func NewRelease(raw RawRelease) Release {
+	rel := Release{
+		Version:  ReleaseVersion,
+		Title:    raw.Title,
+		Magnet:   raw.MagnetURI,
+		Quality:  ParseQuality(raw.Title),
+		Indexer:  raw.Indexer,
+		Size:     raw.Size,
+		Seeders:  raw.Seeders,
+		InfoHash: NormalizeInfoHash(raw.InfoHash),
+	}
+	if raw.Peers > raw.Seeders {
+		rel.Leechers = raw.Peers - raw.Seeders
+	}
+	if !raw.PublishDate.IsZero() {
+		published := raw.PublishDate.UTC()
+		rel.PublishedAt = &published
+	}
+
+	if strings.HasPrefix(strings.ToLower(raw.Link), "magnet:") {
+		if rel.Magnet == "" {
+			rel.Magnet = raw.Link
+		}
+	} else {
+		rel.DownloadURL = raw.Link
+	}
+
+	if m, err := url.Parse(rel.Magnet); err == nil && rel.Magnet != "" {
+		q := m.Query()
+		rel.Trackers = q["tr"]
+		if rel.InfoHash == "" {
+			for _, xt := range q["xt"] {
+				if hash, ok := strings.CutPrefix(strings.ToLower(xt), "urn:btih:"); ok {
+					rel.InfoHash = NormalizeInfoHash(hash)
+				}
+			}
+		}
+	}
+	if rel.Magnet == "" && rel.InfoHash != "" {
+		rel.Magnet = "magnet:?xt=urn:btih:" + rel.InfoHash + "&dn=" + url.QueryEscape(raw.Title)
+	}
+	return rel
+}
+
+// NormalizeInfoHash returns a v1 info hash as lowercase hex, decoding the
+// base32 form some magnets use, or "" when s is neither.
+func NormalizeInfoHash(s string) string {
+	s = strings.TrimSpace(s)
+	switch len(s) {
+	case 40:
+		if _, err := hex.DecodeString(s); err == nil {
+			return strings.ToLower(s)
+		}
+	case 32:
+		if b, err := base32.StdEncoding.DecodeString(strings.ToUpper(s)); err == nil {
+			return hex.EncodeToString(b)
+		}
+	}
+	return ""
+}
+
+// FromJackett converts a Jackett result.
+func FromJackett(r jackett.Result) Release {
+	return NewRelease(RawRelease{
+		Indexer:     r.Tracker,
+		Title:       r.Title,
+		Link:        r.Link,
+		MagnetURI:   r.MagnetURI,
+		InfoHash:    r.InfoHash,
+		Size:        r.Size,
+		Seeders:     r.Seeders,
+		Peers:       r.Peers,
+		PublishDate: r.PublishDate,
+	})
+}
+
+// FromJackettAll converts Jackett results, keeping their order.
+func FromJackettAll(results []jackett.Result) []Release {
+	releases := make([]Release, len(results))
+	for i, r := range results {
+		releases[i] = FromJackett(r)
+	}
+	return releases
+}
@@ -0,0 +1,107 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+const (
+	testHash   = "c12fe1c06bba254a9dc9f519b335aa7c1367a88a"
+	testBase32 = "YEX6DQDLXISUVHOJ6UM3GNNKPQJWPKEK"
+)
+
+func TestNormalizeInfoHash(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{testHash, testHash},
+		{"C12FE1C06BBA254A9DC9F519B335AA7C1367A88A", testHash},
+		{"  " + testHash + "\n", testHash},
+		{testBase32, testHash},
+		{"yex6dqdlxisuvhoj6um3gnnkpqjwpkek", testHash},
+		{"", ""},
+		{testHash[:39], ""},
+		{"z12fe1c06bba254a9dc9f519b335aa7c1367a88a", ""},
+		// 32 characters, but 1 is not in the base32 alphabet.
+		{"1EX6DQDLXISUVHOJ6UM3GNNKPQJWPKEK", ""},
+		// A v2 (SHA-256) hash is left out.
+		{testHash + testHash[:24], ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizeInfoHash(tt.in); got != tt.want {
+			t.Errorf("NormalizeInfoHash(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNewRelease(t *testing.T) {
+	published := time.Date(2024, time.May, 1, 12, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	publishedUTC := published.UTC()
+	magnet := "magnet:?xt=urn:btih:" + testBase32 + "&dn=Film&tr=udp%3A%2F%2Fa%3A1&tr=udp%3A%2F%2Fb%3A2"
+
+	tests := []struct {
+		name string
+		raw  RawRelease
+		want Release
+	}{
+		{
+			name: "hash read from the magnet",
+			raw:  RawRelease{Indexer: "YTS", Title: "Film.2001.1080p.BluRay.x264", MagnetURI: magnet, Size: 100, Seeders: 10, Peers: 14, PublishDate: published},
+			want: Release{
+				Version: ReleaseVersion, Title: "Film.2001.1080p.BluRay.x264", InfoHash: testHash, Magnet: magnet,
+				Quality: Quality{Resolution: "1080p", Codec: "x264", Source: "bluray"},
+				Indexer: "YTS", Trackers: []string{"udp://a:1", "udp://b:2"}, Size: 100, Seeders: 10, Leechers: 4, PublishedAt: &publishedUTC,
+			},
+		},
+		{
+			name: "magnet link",
+			raw:  RawRelease{Title: "Film", Link: magnet, InfoHash: testHash},
+			want: Release{
+				Version: ReleaseVersion, Title: "Film", InfoHash: testHash, Magnet: magnet,
+				Trackers: []string{"udp://a:1", "udp://b:2"},
+			},
+		},
+		{
+			name: "magnet built from the hash",
+			raw:  RawRelease{Title: "Film 2001", Link: "/dl/yts?path=x", InfoHash: testBase32, Seeders: 5, Peers: 3},
+			want: Release{
+				Version: ReleaseVersion, Title: "Film 2001", InfoHash: testHash,
+				Magnet: "magnet:?xt=urn:btih:" + testHash + "&dn=Film+2001", DownloadURL: "/dl/yts?path=x", Seeders: 5,
+			},
+		},
+		{
+			name: "download only",
+			raw:  RawRelease{Title: "Film", Link: "/dl/yts?path=x"},
+			want: Release{Version: ReleaseVersion, Title: "Film", DownloadURL: "/dl/yts?path=x"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewRelease(tt.raw); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewRelease() = %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseQuality(t *testing.T) {
+	tests := []struct {
+		title string
+		want  Quality
+	}{
+		{"Film.2001.2160p.UHD.BluRay.REMUX.HEVC", Quality{"2160p", "x265", "remux"}},
+		{"Film 2001 4K WEB-DL H.264", Quality{"2160p", "x264", "web-dl"}},
+		{"Film.2001.720p.WEBRip.x264", Quality{"720p", "x264", "webrip"}},
+		{"Film.2001.DVDRip.XviD", Quality{"", "xvid", "dvd"}},
+		{"Film.2001.HDCAM.DivX", Quality{"", "xvid", "cam"}},
+		{"Film 2001 SD HDTV AV1", Quality{"480p", "av1", "hdtv"}},
+		{"Film (2001)", Quality{}},
+	}
+	for _, tt := range tests {
+		if got := ParseQuality(tt.title); got != tt.want {
+			t.Errorf("ParseQuality(%q) = %+v, want %+v", tt.title, got, tt.want)
+		}
+	}
+}
@@ -1,21 +1,18 @@
 package model
 
-import (
-	jackett "github.com/webtor-io/go-jackett"
-)
-
 type ContentType string
 
 const (
 	ContentTypeMovies ContentType = "movies"
 	ContentTypeTV     ContentType = "tv"
+	ContentTypeAnime  ContentType = "anime"
 )
 
 type SearchResult struct {
 	Query         string
 	Category      string
 	CategoryTitle string
-	Results       []jackett.Result
+	Results       []Release
 	Count         int
 	Error         string
 }
@@ -24,6 +21,7 @@ func ContentTypeTitle(ct ContentType) string {
 	titles := map[ContentType]string{
 		ContentTypeMovies: "Movies",
 		ContentTypeTV:     "TV Series",
+		ContentTypeAnime:  "Anime",
 	}
 	if title, ok := titles[ct]; ok {
 		return title
@@ -37,6 +35,8 @@ func ParseContentType(s string) (ContentType, bool) {
 		return ContentTypeMovies, true
 	case "tv":
 		return ContentTypeTV, true
+	case "anime":
+		return ContentTypeAnime, true
 	default:
 		return "", false
 	}
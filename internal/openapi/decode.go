@@ -0,0 +1,172 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ParamError reports a parameter that is missing or does not fit its
+// field.
+type ParamError struct {
+	Name  string
+	Value string
+	// Missing is set for a required parameter that was not given.
+	Missing bool
+	// Enum lists the values the parameter takes, if it is an enum.
+	Enum []string
+}
+
+func (e *ParamError) Error() string {
+	switch {
+	case e.Missing:
+		return fmt.Sprintf("%s parameter is required", e.Name)
+	case len(e.Enum) > 1:
+		return fmt.Sprintf("invalid %s: %q (use %s or %s)", e.Name, e.Value,
+			strings.Join(e.Enum[:len(e.Enum)-1], ", "), e.Enum[len(e.Enum)-1])
+	default:
+		return fmt.Sprintf("invalid %s: %q", e.Name, e.Value)
+	}
+}
+
+// Decode fills the request model v points to from r, reading the same
+// tags the document is built from: path fields through pathParam, query
+// fields from the query string. Parameters that are absent leave their
+// field as it was, so defaults can be set beforehand; path parameters and
+// fields tagged required:"true" must be given. Values outside a field's
+// enum are rejected. Slices take repeated and comma-separated values.
+func Decode(r *http.Request, v interface{}, pathParam func(*http.Request, string) string) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("openapi: Decode needs a pointer to a struct, not %T", v)
+	}
+	return decodeFields(r, rv.Elem(), pathParam)
+}
+
+// QueryParams lists the query parameter names of a request model.
+func QueryParams(model interface{}) []string {
+	var names []string
+	for _, f := range reflect.VisibleFields(reflect.TypeOf(model)) {
+		if n := f.Tag.Get("query"); n != "" {
+			names = append(names, n)
+		}
+	}
+	return names
+}
+
+func decodeFields(r *http.Request, v reflect.Value, pathParam func(*http.Request, string) string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			if err := decodeFields(r, v.Field(i), pathParam); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var name string
+		var values []string
+		required := f.Tag.Get("required") == "true"
+		if name = f.Tag.Get("path"); name != "" {
+			if s := pathParam(r, name); s != "" {
+				values = []string{s}
+			}
+			required = true
+		} else if name = f.Tag.Get("query"); name != "" {
+			for _, s := range r.URL.Query()[name] {
+				if s = strings.TrimSpace(s); s != "" {
+					values = append(values, s)
+				}
+			}
+		} else {
+			continue
+		}
+		if len(values) == 0 {
+			if required {
+				return &ParamError{Name: name, Missing: true}
+			}
+			continue
+		}
+
+		var enum []string
+		if e := f.Tag.Get("enum"); e != "" {
+			enum = strings.Split(e, ",")
+		}
+		if err := set(v.Field(i), values, enum); err != nil {
+			return &ParamError{Name: name, Value: strings.Join(values, ","), Enum: enum}
+		}
+	}
+	return nil
+}
+
+// set parses values into field: the first value for scalars, every
+// comma-separated item for slices.
+func set(field reflect.Value, values, enum []string) error {
+	if field.Kind() == reflect.Slice {
+		items := reflect.MakeSlice(field.Type(), 0, len(values))
+		for _, value := range values {
+			for _, s := range strings.Split(value, ",") {
+				if s = strings.TrimSpace(s); s == "" {
+					continue
+				}
+				item := reflect.New(field.Type().Elem()).Elem()
+				if err := setScalar(item, s, enum); err != nil {
+					return err
+				}
+				items = reflect.Append(items, item)
+			}
+		}
+		field.Set(items)
+		return nil
+	}
+	return setScalar(field, values[0], enum)
+}
+
+func setScalar(field reflect.Value, s string, enum []string) error {
+	if len(enum) > 0 && !contains(enum, s) {
+		return fmt.Errorf("%q is not one of %v", s, enum)
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(n)
+	default:
+		return fmt.Errorf("openapi: cannot decode into %s", field.Type())
+	}
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package openapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+type (
+	decodeID struct {
+		ID int `path:"id"`
+	}
+	decodeModel struct {
+		decodeID
+		Query   string   `query:"q" required:"true"`
+		Page    int      `query:"page"`
+		Min     uint     `query:"min"`
+		Sort    string   `query:"sort" enum:"year,popularity"`
+		Tags    []string `query:"tag"`
+		IDs     []int    `query:"ids"`
+		Format  string   `query:"format" enum:"json,html"`
+		Ignored string
+	}
+)
+
+func TestDecode(t *testing.T) {
+	paths := map[string]string{"id": "42"}
+	pathParam := func(_ *http.Request, key string) string { return paths[key] }
+
+	tests := []struct {
+		name    string
+		query   string
+		preset  decodeModel
+		want    decodeModel
+		wantErr string
+	}{
+		{
+			name:  "scalars",
+			query: "q=dune&page=2&min=5&sort=popularity",
+			want:  decodeModel{decodeID: decodeID{42}, Query: "dune", Page: 2, Min: 5, Sort: "popularity"},
+		},
+		{
+			name:   "absent keeps presets",
+			query:  "q=dune",
+			preset: decodeModel{Sort: "year", Page: 1},
+			want:   decodeModel{decodeID: decodeID{42}, Query: "dune", Sort: "year", Page: 1},
+		},
+		{
+			name:   "blank is absent",
+			query:  "q=dune&sort=",
+			preset: decodeModel{Sort: "year"},
+			want:   decodeModel{decodeID: decodeID{42}, Query: "dune", Sort: "year"},
+		},
+		{
+			name:  "lists repeated and comma-separated",
+			query: "q=x&tag=a,b&tag=c&ids=1,%202,,3",
+			want:  decodeModel{decodeID: decodeID{42}, Query: "x", Tags: []string{"a", "b", "c"}, IDs: []int{1, 2, 3}},
+		},
+		{name: "missing required", query: "page=1", wantErr: "q parameter is required"},
+		{name: "bad int", query: "q=x&page=two", wantErr: `invalid page: "two"`},
+		{name: "negative uint", query: "q=x&min=-1", wantErr: `invalid min: "-1"`},
+		{name: "bad list item", query: "q=x&ids=1,b", wantErr: `invalid ids: "1,b"`},
+		{name: "outside enum", query: "q=x&sort=rating", wantErr: `invalid sort: "rating" (use year or popularity)`},
+		{name: "two-value enum", query: "q=x&format=xml", wantErr: `invalid format: "xml" (use json or html)`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/?"+tt.query, nil)
+			got := tt.preset
+			err := Decode(r, &got, pathParam)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("Decode() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Decode() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeMissingPath(t *testing.T) {
+	r := httptest.NewRequest("GET", "/?q=x", nil)
+	var got decodeModel
+	err := Decode(r, &got, func(*http.Request, string) string { return "" })
+	if err == nil || err.Error() != "id parameter is required" {
+		t.Fatalf("Decode() error = %v, want the missing id", err)
+	}
+}
+
+func TestQueryParams(t *testing.T) {
+	want := []string{"q", "page", "min", "sort", "tag", "ids", "format"}
+	if got := QueryParams(decodeModel{}); !reflect.DeepEqual(got, want) {
+		t.Errorf("QueryParams() = %v, want %v", got, want)
+	}
+}
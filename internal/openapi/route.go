@@ -0,0 +1,200 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Media types routes produce besides JSON.
+const (
+	JSON        = "application/json"
+	HTML        = "text/html"
+	EventStream = "text/event-stream"
+	Calendar    = "text/calendar"
+	Torrent     = "application/x-bittorrent"
+)
+
+// Route is one endpoint: how to serve it and how to document it.
+//
+// Params is the request model, a struct whose fields carry path:"name"
+// or query:"name" tags with an optional doc:"..." description, enum:"a,b"
+// values and required:"true"; embedded structs are flattened. Handlers
+// read it with Decode. Response is the JSON
+// body, nil when the route has no fixed schema.
+type Route struct {
+	Method  string
+	Path    string
+	Name    string
+	Summary string
+	Tag     string
+
+	Params   interface{}
+	Response interface{}
+	// Produces lists the media types of the body, JSON when empty.
+	Produces []string
+	// Status is the success status, 200 when zero.
+	Status int
+
+	// Legacy is the unversioned path the route is also served at, as a
+	// deprecated alias.
+	Legacy string
+
+	Handler http.HandlerFunc
+}
+
+// Group is routes served under a common path prefix.
+type Group struct {
+	Prefix string
+	Routes []Route
+}
+
+// Builder collects routes into a document.
+type Builder struct {
+	doc     *Document
+	schemas *schemas
+	tags    map[string]bool
+}
+
+func NewBuilder(info Info) *Builder {
+	return &Builder{
+		doc:     &Document{OpenAPI: Version, Info: info, Paths: make(map[string]*PathItem)},
+		schemas: newSchemas(),
+		tags:    make(map[string]bool),
+	}
+}
+
+// Substitute documents values of type from as if they were of type to,
+// for types that encode themselves with MarshalJSON.
+func (b *Builder) Substitute(from, to interface{}) {
+	b.schemas.substitutes[reflect.TypeOf(from)] = reflect.TypeOf(to)
+}
+
+// Tag describes a tag; tags are listed in the order described.
+func (b *Builder) Tag(name, description string) {
+	b.doc.Tags = append(b.doc.Tags, Tag{Name: name, Description: description})
+	b.tags[name] = true
+}
+
+// Add documents a group of routes, and each route's legacy alias as a
+// deprecated operation.
+func (b *Builder) Add(g Group) {
+	for _, rt := range g.Routes {
+		op := b.operation(rt)
+		b.put(rt.Method, g.Prefix+rt.Path, op)
+		if rt.Legacy != "" {
+			alias := *op
+			alias.OperationID += "Legacy"
+			alias.Deprecated = true
+			alias.Description = strings.TrimSpace(alias.Description + "\n\nDeprecated alias of `" + g.Prefix + rt.Path + "`.")
+			b.put(rt.Method, rt.Legacy, &alias)
+		}
+	}
+}
+
+// Error documents the JSON body of error responses.
+func (b *Builder) Error(body interface{}) {
+	schema := b.schemas.of(reflect.TypeOf(body))
+	for _, item := range b.doc.Paths {
+		for _, op := range *item {
+			if _, ok := op.Responses["default"]; ok {
+				continue
+			}
+			op.Responses["default"] = Response{
+				Description: "Error",
+				Content:     map[string]MediaType{JSON: {Schema: schema}},
+			}
+		}
+	}
+}
+
+// Document returns the document built so far.
+func (b *Builder) Document() *Document {
+	b.doc.Components.Schemas = b.schemas.components
+	return b.doc
+}
+
+func (b *Builder) put(method, p string, op *Operation) {
+	item, ok := b.doc.Paths[p]
+	if !ok {
+		item = &PathItem{}
+		b.doc.Paths[p] = item
+	}
+	(*item)[strings.ToLower(method)] = op
+}
+
+func (b *Builder) operation(rt Route) *Operation {
+	op := &Operation{
+		OperationID: rt.Name,
+		Summary:     rt.Summary,
+		Responses:   make(map[string]Response),
+	}
+	if rt.Tag != "" {
+		op.Tags = []string{rt.Tag}
+		if !b.tags[rt.Tag] {
+			b.Tag(rt.Tag, "")
+		}
+	}
+	if rt.Params != nil {
+		op.Parameters = b.params(reflect.TypeOf(rt.Params))
+	}
+
+	status := rt.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	resp := Response{Description: http.StatusText(status)}
+	produces := rt.Produces
+	if len(produces) == 0 {
+		produces = []string{JSON}
+	}
+	if status < 300 || status >= 400 {
+		resp.Content = make(map[string]MediaType)
+		for _, mt := range produces {
+			schema := &Schema{Type: "string"}
+			if mt == JSON {
+				schema = &Schema{Type: "object"}
+				if rt.Response != nil {
+					schema = b.schemas.of(reflect.TypeOf(rt.Response))
+				}
+			}
+			resp.Content[mt] = MediaType{Schema: schema}
+		}
+	} else {
+		resp.Headers = map[string]Header{"Location": {Schema: &Schema{Type: "string"}}}
+	}
+	op.Responses[strconv.Itoa(status)] = resp
+	return op
+}
+
+// params reads a request model's path and query parameters, path first.
+func (b *Builder) params(t reflect.Type) []Parameter {
+	var params []Parameter
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			params = append(params, b.params(f.Type)...)
+			continue
+		}
+		p := Parameter{Description: f.Tag.Get("doc"), Schema: b.schemas.of(f.Type)}
+		if n := f.Tag.Get("path"); n != "" {
+			p.Name, p.In, p.Required = n, "path", true
+		} else if n := f.Tag.Get("query"); n != "" {
+			p.Name, p.In, p.Required = n, "query", f.Tag.Get("required") == "true"
+		} else {
+			continue
+		}
+		if enum := f.Tag.Get("enum"); enum != "" {
+			if p.Schema.Items != nil {
+				p.Schema.Items.Enum = strings.Split(enum, ",")
+			} else {
+				p.Schema.Enum = strings.Split(enum, ",")
+			}
+		}
+		params = append(params, p)
+	}
+	sort.SliceStable(params, func(i, j int) bool { return params[i].In == "path" && params[j].In != "path" })
+	return params
+}
@@ -0,0 +1,122 @@
+package openapi
+
+import (
+	"net/http"
+	"testing"
+)
+
+type (
+	routeID struct {
+		ID int `path:"id" doc:"Item ID"`
+	}
+	routeRequest struct {
+		Sort string `query:"sort" enum:"new,old" doc:"Order"`
+		routeID
+		Name  string   `query:"name" required:"true"`
+		Kinds []string `query:"kind" enum:"a,b"`
+		Other string
+	}
+	routeItem struct {
+		ID int `json:"id"`
+	}
+	routeError struct {
+		Error string `json:"error"`
+	}
+)
+
+func testDocument() *Document {
+	b := NewBuilder(Info{Title: "Test", Version: "1"})
+	b.Tag("items", "Items")
+	b.Add(Group{Prefix: "/api/v1", Routes: []Route{
+		{Method: "GET", Path: "/items/{id}", Name: "getItem", Summary: "An item", Tag: "items",
+			Params: routeRequest{}, Response: routeItem{}, Legacy: "/api/items/{id}"},
+		{Method: "GET", Path: "/items/{id}/page", Name: "itemPage", Tag: "pages",
+			Params: routeID{}, Produces: []string{HTML, JSON}},
+	}})
+	b.Add(Group{Routes: []Route{
+		{Method: "POST", Path: "/settings", Name: "saveSettings", Status: http.StatusSeeOther},
+	}})
+	b.Error(routeError{})
+	return b.Document()
+}
+
+func TestBuilderParameters(t *testing.T) {
+	op := (*testDocument().Paths["/api/v1/items/{id}"])["get"]
+	want := `[` +
+		`{"name":"id","in":"path","description":"Item ID","required":true,"schema":{"type":"integer"}},` +
+		`{"name":"sort","in":"query","description":"Order","schema":{"type":"string","enum":["new","old"]}},` +
+		`{"name":"name","in":"query","required":true,"schema":{"type":"string"}},` +
+		`{"name":"kind","in":"query","schema":{"type":"array","items":{"type":"string","enum":["a","b"]}}}]`
+	if got := encode(t, op.Parameters); got != want {
+		t.Errorf("parameters = %s\nwant %s", got, want)
+	}
+}
+
+func TestBuilderOperations(t *testing.T) {
+	doc := testDocument()
+	tests := []struct {
+		path, method string
+		want         string
+	}{
+		{
+			path: "/api/v1/items/{id}", method: "get",
+			want: `{"operationId":"getItem","summary":"An item","tags":["items"],"responses":{` +
+				`"200":{"description":"OK","content":{"application/json":{"schema":{"$ref":"#/components/schemas/openapi.routeItem"}}}},` +
+				`"default":{"description":"Error","content":{"application/json":{"schema":{"$ref":"#/components/schemas/openapi.routeError"}}}}}}`,
+		},
+		{
+			path: "/api/v1/items/{id}/page", method: "get",
+			want: `{"operationId":"itemPage","tags":["pages"],"responses":{` +
+				`"200":{"description":"OK","content":{"application/json":{"schema":{"type":"object"}},"text/html":{"schema":{"type":"string"}}}},` +
+				`"default":{"description":"Error","content":{"application/json":{"schema":{"$ref":"#/components/schemas/openapi.routeError"}}}}}}`,
+		},
+		{
+			path: "/settings", method: "post",
+			want: `{"operationId":"saveSettings","responses":{` +
+				`"303":{"description":"See Other","headers":{"Location":{"schema":{"type":"string"}}}},` +
+				`"default":{"description":"Error","content":{"application/json":{"schema":{"$ref":"#/components/schemas/openapi.routeError"}}}}}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			item, ok := doc.Paths[tt.path]
+			if !ok {
+				t.Fatalf("no path %s", tt.path)
+			}
+			op := *(*item)[tt.method]
+			op.Parameters = nil
+			if got := encode(t, op); got != tt.want {
+				t.Errorf("operation = %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuilderLegacyAlias(t *testing.T) {
+	doc := testDocument()
+	current := (*doc.Paths["/api/v1/items/{id}"])["get"]
+	legacy := (*doc.Paths["/api/items/{id}"])["get"]
+	if legacy == nil {
+		t.Fatal("legacy path not documented")
+	}
+	if current.Deprecated {
+		t.Error("versioned operation is deprecated")
+	}
+	if !legacy.Deprecated || legacy.OperationID != "getItemLegacy" {
+		t.Errorf("legacy operation = %s, deprecated %v", legacy.OperationID, legacy.Deprecated)
+	}
+	if want := "Deprecated alias of `/api/v1/items/{id}`."; legacy.Description != want {
+		t.Errorf("legacy description = %q, want %q", legacy.Description, want)
+	}
+	if encode(t, legacy.Parameters) != encode(t, current.Parameters) {
+		t.Error("legacy parameters differ from the versioned ones")
+	}
+}
+
+func TestBuilderTags(t *testing.T) {
+	// Described tags come first, in order; others are added as used.
+	want := `[{"name":"items","description":"Items"},{"name":"pages"}]`
+	if got := encode(t, testDocument().Tags); got != want {
+		t.Errorf("tags = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,128 @@
+package openapi
+
+import (
+	"encoding/json"
+	"path"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// schemas turns Go types into JSON Schemas the way encoding/json would
+// encode them. Named structs become components referenced by $ref.
+type schemas struct {
+	components map[string]*Schema
+	// substitutes stand in for types with their own MarshalJSON.
+	substitutes map[reflect.Type]reflect.Type
+}
+
+func newSchemas() *schemas {
+	return &schemas{
+		components:  make(map[string]*Schema),
+		substitutes: make(map[reflect.Type]reflect.Type),
+	}
+}
+
+// name is a named type's component name, such as model.Release.
+func name(t reflect.Type) string {
+	n := t.Name()
+	if i := strings.IndexByte(n, '['); i >= 0 {
+		n = n[:i]
+	}
+	return path.Base(t.PkgPath()) + "." + n
+}
+
+func (s *schemas) of(t reflect.Type) *Schema {
+	if sub, ok := s.substitutes[t]; ok {
+		t = sub
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+		if sub, ok := s.substitutes[t]; ok {
+			t = sub
+		}
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t.Implements(marshalerType):
+		// Encodes itself in a way reflection cannot see.
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: s.of(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.of(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+		n := name(t)
+		if _, ok := s.components[n]; !ok {
+			// Reserve the name first so recursive types end.
+			s.components[n] = &Schema{}
+			*s.components[n] = *s.object(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + n}
+	default:
+		return &Schema{}
+	}
+}
+
+// object builds a struct's schema from its JSON fields, flattening
+// embedded structs as encoding/json does.
+func (s *schemas) object(t reflect.Type) *Schema {
+	obj := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	s.fields(t, obj)
+	return obj
+}
+
+func (s *schemas) fields(t reflect.Type, obj *Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		fieldName, opts, _ := strings.Cut(tag, ",")
+
+		ft := f.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && fieldName == "" && ft.Kind() == reflect.Struct && !reflect.PointerTo(ft).Implements(marshalerType) {
+			s.fields(ft, obj)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if fieldName == "" {
+			fieldName = f.Name
+		}
+		obj.Properties[fieldName] = s.of(f.Type)
+		if !strings.Contains(opts, "omitempty") {
+			obj.Required = append(obj.Required, fieldName)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type (
+	schemaInner struct {
+		Name string `json:"name"`
+	}
+	schemaBase struct {
+		ID int `json:"id"`
+	}
+	schemaOuter struct {
+		schemaBase
+		Title    string         `json:"title"`
+		Rating   float64        `json:"rating,omitempty"`
+		Tags     []string       `json:"tags"`
+		Inner    *schemaInner   `json:"inner,omitempty"`
+		Counts   map[string]int `json:"counts"`
+		At       time.Time      `json:"at"`
+		Raw      []byte         `json:"raw,omitempty"`
+		Skipped  string         `json:"-"`
+		private  string
+		Untagged bool
+	}
+	schemaNode struct {
+		Children []schemaNode `json:"children"`
+	}
+	schemaPage[T any] struct {
+		Results []T `json:"results"`
+	}
+	schemaCustom struct{}
+	schemaShown  struct {
+		Shown string `json:"shown"`
+	}
+)
+
+func (schemaCustom) MarshalJSON() ([]byte, error) { return []byte(`"custom"`), nil }
+
+func TestSchemaOf(t *testing.T) {
+	tests := []struct {
+		name       string
+		value      interface{}
+		want       string
+		components map[string]string
+	}{
+		{name: "bool", value: true, want: `{"type":"boolean"}`},
+		{name: "uint", value: uint(1), want: `{"type":"integer"}`},
+		{name: "float", value: 1.5, want: `{"type":"number"}`},
+		{name: "string pointer", value: new(string), want: `{"type":"string"}`},
+		{name: "time", value: time.Time{}, want: `{"type":"string","format":"date-time"}`},
+		{name: "bytes", value: []byte{}, want: `{"type":"string","format":"byte"}`},
+		{name: "list", value: []int{}, want: `{"type":"array","items":{"type":"integer"}}`},
+		{name: "map", value: map[string]bool{}, want: `{"type":"object","additionalProperties":{"type":"boolean"}}`},
+		{name: "own marshaler", value: schemaCustom{}, want: `{}`},
+		{name: "anonymous struct", value: struct {
+			A string `json:"a"`
+		}{}, want: `{"type":"object","properties":{"a":{"type":"string"}},"required":["a"]}`},
+		{
+			name:  "named struct",
+			value: schemaOuter{},
+			want:  `{"$ref":"#/components/schemas/openapi.schemaOuter"}`,
+			components: map[string]string{
+				"openapi.schemaOuter": `{"type":"object","properties":{` +
+					`"Untagged":{"type":"boolean"},"at":{"type":"string","format":"date-time"},` +
+					`"counts":{"type":"object","additionalProperties":{"type":"integer"}},"id":{"type":"integer"},` +
+					`"inner":{"$ref":"#/components/schemas/openapi.schemaInner"},"rating":{"type":"number"},` +
+					`"raw":{"type":"string","format":"byte"},"tags":{"type":"array","items":{"type":"string"}},` +
+					`"title":{"type":"string"}},` +
+					`"required":["id","title","tags","counts","at","Untagged"]}`,
+				"openapi.schemaInner": `{"type":"object","properties":{"name":{"type":"string"}},"required":["name"]}`,
+			},
+		},
+		{
+			name:  "recursive struct",
+			value: []schemaNode{},
+			want:  `{"type":"array","items":{"$ref":"#/components/schemas/openapi.schemaNode"}}`,
+			components: map[string]string{
+				"openapi.schemaNode": `{"type":"object","properties":{"children":{"type":"array","items":{"$ref":"#/components/schemas/openapi.schemaNode"}}},"required":["children"]}`,
+			},
+		},
+		{
+			name:  "generic struct",
+			value: schemaPage[int]{},
+			want:  `{"$ref":"#/components/schemas/openapi.schemaPage"}`,
+			components: map[string]string{
+				"openapi.schemaPage": `{"type":"object","properties":{"results":{"type":"array","items":{"type":"integer"}}},"required":["results"]}`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newSchemas()
+			if got := encode(t, s.of(reflect.TypeOf(tt.value))); got != tt.want {
+				t.Errorf("schema = %s\nwant %s", got, tt.want)
+			}
+			if len(s.components) != len(tt.components) {
+				t.Errorf("%d components, want %d", len(s.components), len(tt.components))
+			}
+			for name, want := range tt.components {
+				if got := encode(t, s.components[name]); got != want {
+					t.Errorf("component %s = %s\nwant %s", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSchemaSubstitute(t *testing.T) {
+	s := newSchemas()
+	s.substitutes[reflect.TypeOf(schemaCustom{})] = reflect.TypeOf(schemaShown{})
+	want := `{"type":"array","items":{"$ref":"#/components/schemas/openapi.schemaShown"}}`
+	if got := encode(t, s.of(reflect.TypeOf([]*schemaCustom{}))); got != want {
+		t.Errorf("schema = %s, want %s", got, want)
+	}
+}
+
+func encode(t *testing.T, v interface{}) string {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
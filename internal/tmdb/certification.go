@@ -0,0 +1,116 @@
+package tmdb
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	// certificationTTL is how long a title's certification is reused. Ratings
+	// rarely change, and filtering a page of results looks up every title.
+	certificationTTL = 24 * time.Hour
+	// titleCertCacheSize caps how many titles' certifications are kept.
+	titleCertCacheSize = 4096
+)
+
+type certificationList struct {
+	countries map[string][]Certification
+	fetchedAt time.Time
+}
+
+// GetCertifications returns the rating systems for "movie" or "tv", keyed
+// by country and sorted from least to most restrictive.
+func (c *Client) GetCertifications(mediaType string) (map[string][]Certification, error) {
+	if mediaType != "movie" && mediaType != "tv" {
+		return nil, fmt.Errorf("unknown media type: %s", mediaType)
+	}
+
+	c.certMu.Lock()
+	cached, ok := c.certLists[mediaType]
+	c.certMu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < certificationTTL {
+		return cached.countries, nil
+	}
+
+	url := fmt.Sprintf("%s/certification/%s/list", baseURL, mediaType)
+	var result struct {
+		Certifications map[string][]Certification `json:"certifications"`
+	}
+	if err := c.doRequest(url, &result); err != nil {
+		if ok {
+			return cached.countries, nil
+		}
+		return nil, err
+	}
+	for _, certs := range result.Certifications {
+		sort.SliceStable(certs, func(i, j int) bool { return certs[i].Order < certs[j].Order })
+	}
+
+	c.certMu.Lock()
+	c.certLists[mediaType] = &certificationList{countries: result.Certifications, fetchedAt: time.Now()}
+	c.certMu.Unlock()
+	return result.Certifications, nil
+}
+
+// CertificationRank returns cert's position in country's rating system,
+// or -1 when the rating is unknown or means "not rated".
+func (c *Client) CertificationRank(mediaType, country, cert string) int {
+	if cert == "" {
+		return -1
+	}
+	lists, err := c.GetCertifications(mediaType)
+	if err != nil {
+		return -1
+	}
+	for _, ct := range lists[country] {
+		if ct.Certification == cert {
+			if ct.Order < 1 {
+				return -1
+			}
+			return ct.Order
+		}
+	}
+	return -1
+}
+
+func (c *Client) GetTVContentRatings(id int) (*ContentRatingsResponse, error) {
+	url := fmt.Sprintf("%s/tv/%d/content_ratings", baseURL, id)
+
+	var result ContentRatingsResponse
+	if err := c.doRequest(url, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// TitleCertification returns a title's rating in country, or "" when it is
+// unrated there. Results are cached so list filtering stays cheap.
+func (c *Client) TitleCertification(mediaType string, id int, country string) (string, error) {
+	key := fmt.Sprintf("%s/%d/%s", mediaType, id, country)
+
+	if value, ok := c.titleCerts.Get(key); ok {
+		return value, nil
+	}
+
+	var value string
+	switch mediaType {
+	case "movie":
+		dates, err := c.GetMovieReleaseDates(id)
+		if err != nil {
+			return "", err
+		}
+		value = dates.Certification(country)
+	case "tv":
+		ratings, err := c.GetTVContentRatings(id)
+		if err != nil {
+			return "", err
+		}
+		value = ratings.Rating(country)
+	default:
+		return "", fmt.Errorf("unknown media type: %s", mediaType)
+	}
+
+	c.titleCerts.Put(key, value)
+	return value, nil
+}
@@ -2,17 +2,32 @@ package tmdb
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
 	"time"
+
+	"github.com/unedtamps/orbit/internal/ttlcache"
 )
 
 const baseURL = "https://api.themoviedb.org/3"
 
+// ErrNoAPIKey is returned by every request when no TMDB key is configured,
+// so callers can fall back without waiting on a doomed request.
+var ErrNoAPIKey = errors.New("TMDB API key not configured")
+
 type Client struct {
-	apiKey  string
-	http    *http.Client
+	apiKey string
+	http   *http.Client
+
+	genreMu sync.Mutex
+	genres  map[string]*genreList
+
+	certMu     sync.Mutex
+	certLists  map[string]*certificationList
+	titleCerts *ttlcache.Cache[string]
 }
 
 func NewClient(apiKey string) *Client {
@@ -21,10 +36,22 @@ func NewClient(apiKey string) *Client {
 		http: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		genres:     make(map[string]*genreList),
+		certLists:  make(map[string]*certificationList),
+		titleCerts: ttlcache.New[string](certificationTTL, titleCertCacheSize),
 	}
 }
 
+// Name identifies TMDB as a metadata provider.
+func (c *Client) Name() string { return "tmdb" }
+
+// Enabled reports whether an API key is configured.
+func (c *Client) Enabled() bool { return c.apiKey != "" }
+
 func (c *Client) doRequest(url string, result interface{}) error {
+	if c.apiKey == "" {
+		return ErrNoAPIKey
+	}
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
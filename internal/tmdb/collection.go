@@ -0,0 +1,13 @@
+package tmdb
+
+import "fmt"
+
+func (c *Client) GetCollection(id int) (*Collection, error) {
+	url := fmt.Sprintf("%s/collection/%d?language=en-US", baseURL, id)
+
+	var result Collection
+	if err := c.doRequest(url, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,57 @@
+package tmdb
+
+import (
+	"fmt"
+	neturl "net/url"
+)
+
+// External ID sources accepted by Find.
+const (
+	SourceIMDb      = "imdb_id"
+	SourceTVDB      = "tvdb_id"
+	SourceWikidata  = "wikidata_id"
+	SourceFacebook  = "facebook_id"
+	SourceInstagram = "instagram_id"
+	SourceTwitter   = "twitter_id"
+	SourceTikTok    = "tiktok_id"
+	SourceYoutube   = "youtube_id"
+)
+
+var findSources = map[string]bool{
+	SourceIMDb: true, SourceTVDB: true, SourceWikidata: true, SourceFacebook: true,
+	SourceInstagram: true, SourceTwitter: true, SourceTikTok: true, SourceYoutube: true,
+}
+
+// Find looks up TMDB objects by an ID from another database.
+func (c *Client) Find(externalID, source string) (*FindResponse, error) {
+	if !findSources[source] {
+		return nil, fmt.Errorf("unknown external source: %s", source)
+	}
+	url := fmt.Sprintf("%s/find/%s?external_source=%s&language=en-US", baseURL, neturl.PathEscape(externalID), source)
+
+	var result FindResponse
+	if err := c.doRequest(url, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *Client) GetTVExternalIDs(id int) (*ExternalIDs, error) {
+	url := fmt.Sprintf("%s/tv/%d/external_ids", baseURL, id)
+
+	var result ExternalIDs
+	if err := c.doRequest(url, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *Client) GetMovieExternalIDs(id int) (*ExternalIDs, error) {
+	url := fmt.Sprintf("%s/movie/%d/external_ids", baseURL, id)
+
+	var result ExternalIDs
+	if err := c.doRequest(url, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
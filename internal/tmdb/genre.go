@@ -0,0 +1,122 @@
+package tmdb
+
+import (
+	"fmt"
+	"time"
+)
+
+// genreTTL is how long a genre list is reused before TMDB is asked again.
+const genreTTL = 24 * time.Hour
+
+// animationGenreID is Animation in both the movie and the TV genre list.
+const animationGenreID = 16
+
+type genreList struct {
+	genres    []Genre
+	names     map[int]string
+	fetchedAt time.Time
+}
+
+// GetGenres returns TMDB's genre list for "movie" or "tv" in language,
+// cached per media type and language.
+func (c *Client) GetGenres(mediaType, language string) ([]Genre, error) {
+	list, err := c.genreList(mediaType, language)
+	if err != nil {
+		return nil, err
+	}
+	return list.genres, nil
+}
+
+func (c *Client) genreList(mediaType, language string) (*genreList, error) {
+	if mediaType != "movie" && mediaType != "tv" {
+		return nil, fmt.Errorf("unknown media type: %s", mediaType)
+	}
+	key := mediaType + "/" + language
+
+	c.genreMu.Lock()
+	cached, ok := c.genres[key]
+	c.genreMu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < genreTTL {
+		return cached, nil
+	}
+
+	url := fmt.Sprintf("%s/genre/%s/list?language=%s", baseURL, mediaType, language)
+	var result struct {
+		Genres []Genre `json:"genres"`
+	}
+	if err := c.doRequest(url, &result); err != nil {
+		if ok {
+			return cached, nil
+		}
+		return nil, err
+	}
+
+	list := &genreList{
+		genres:    result.Genres,
+		names:     make(map[int]string, len(result.Genres)),
+		fetchedAt: time.Now(),
+	}
+	for _, g := range result.Genres {
+		list.names[g.ID] = g.Name
+	}
+
+	c.genreMu.Lock()
+	c.genres[key] = list
+	c.genreMu.Unlock()
+	return list, nil
+}
+
+// GenreName returns the name of a genre ID, or "" when it is unknown.
+func (c *Client) GenreName(mediaType string, id int, language string) string {
+	list, err := c.genreList(mediaType, language)
+	if err != nil {
+		return ""
+	}
+	return list.names[id]
+}
+
+// resolveGenres fills GenreNames on each result. Results without a media
+// type, as returned by discover and per-type lists, use fallbackType.
+// Names are a best-effort addition, so lookup failures leave them empty.
+func (c *Client) resolveGenres(results []SearchResult, fallbackType, language string) {
+	for i := range results {
+		mediaType := results[i].MediaType
+		if mediaType == "" {
+			mediaType = fallbackType
+		}
+		if mediaType != "movie" && mediaType != "tv" {
+			continue
+		}
+		list, err := c.genreList(mediaType, language)
+		if err != nil {
+			return
+		}
+		results[i].GenreNames = make([]string, 0, len(results[i].GenreIDs))
+		for _, id := range results[i].GenreIDs {
+			if name, ok := list.names[id]; ok {
+				results[i].GenreNames = append(results[i].GenreNames, name)
+			}
+		}
+	}
+}
+
+// Discover lists popular titles of one genre for "movie" or "tv".
+func (c *Client) Discover(mediaType string, genreID int, page int) (*MultiSearchResponse, error) {
+	if mediaType != "movie" && mediaType != "tv" {
+		return nil, fmt.Errorf("unknown media type: %s", mediaType)
+	}
+	if page < 1 {
+		page = 1
+	}
+	url := fmt.Sprintf("%s/discover/%s?with_genres=%d&sort_by=popularity.desc&page=%d&language=en-US", baseURL, mediaType, genreID, page)
+
+	var result MultiSearchResponse
+	if err := c.doRequest(url, &result); err != nil {
+		return nil, err
+	}
+	for i := range result.Results {
+		result.Results[i].MediaType = mediaType
+	}
+	c.resolveGenres(result.Results, mediaType, "en-US")
+	return &result, nil
+}
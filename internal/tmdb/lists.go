@@ -0,0 +1,46 @@
+package tmdb
+
+import "fmt"
+
+// getList fetches one of TMDB's per-type lists (upcoming, now_playing,
+// airing_today, on_the_air). Those results carry no media type, so it is
+// filled in before genre names are resolved.
+func (c *Client) getList(mediaType, list, region string, page int) (*MultiSearchResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	url := fmt.Sprintf("%s/%s/%s?page=%d&language=en-US", baseURL, mediaType, list, page)
+	if region != "" {
+		url += "&region=" + region
+	}
+
+	var result MultiSearchResponse
+	if err := c.doRequest(url, &result); err != nil {
+		return nil, err
+	}
+	for i := range result.Results {
+		result.Results[i].MediaType = mediaType
+	}
+	c.resolveGenres(result.Results, mediaType, "en-US")
+	return &result, nil
+}
+
+// GetUpcomingMovies lists movies releasing soon in region.
+func (c *Client) GetUpcomingMovies(region string, page int) (*MultiSearchResponse, error) {
+	return c.getList("movie", "upcoming", region, page)
+}
+
+// GetNowPlayingMovies lists movies currently in theatres in region.
+func (c *Client) GetNowPlayingMovies(region string, page int) (*MultiSearchResponse, error) {
+	return c.getList("movie", "now_playing", region, page)
+}
+
+// GetAiringTodayTV lists shows with an episode airing today.
+func (c *Client) GetAiringTodayTV(page int) (*MultiSearchResponse, error) {
+	return c.getList("tv", "airing_today", "", page)
+}
+
+// GetOnTheAirTV lists shows with an episode airing in the next seven days.
+func (c *Client) GetOnTheAirTV(page int) (*MultiSearchResponse, error) {
+	return c.getList("tv", "on_the_air", "", page)
+}
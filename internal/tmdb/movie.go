@@ -3,7 +3,7 @@ package tmdb
 import "fmt"
 
 func (c *Client) GetMovieDetails(id int) (*MovieDetails, error) {
-	url := fmt.Sprintf("%s/movie/%d?append_to_response=credits,reviews&language=en-US", baseURL, id)
+	url := fmt.Sprintf("%s/movie/%d?append_to_response=credits,reviews,recommendations,similar,watch/providers,videos,release_dates&language=en-US&include_video_language=en,null", baseURL, id)
 
 	var result MovieDetails
 	if err := c.doRequest(url, &result); err != nil {
@@ -12,6 +12,27 @@ func (c *Client) GetMovieDetails(id int) (*MovieDetails, error) {
 	return &result, nil
 }
 
+// GetMovieSummary fetches a movie with only its release dates appended.
+func (c *Client) GetMovieSummary(id int) (*MovieDetails, error) {
+	url := fmt.Sprintf("%s/movie/%d?append_to_response=release_dates&language=en-US", baseURL, id)
+
+	var result MovieDetails
+	if err := c.doRequest(url, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *Client) GetMovieReleaseDates(id int) (*ReleaseDatesResponse, error) {
+	url := fmt.Sprintf("%s/movie/%d/release_dates", baseURL, id)
+
+	var result ReleaseDatesResponse
+	if err := c.doRequest(url, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
 func (c *Client) GetMovieReviews(id int, page int) (*ReviewResponse, error) {
 	if page < 1 {
 		page = 1
@@ -24,3 +45,41 @@ func (c *Client) GetMovieReviews(id int, page int) (*ReviewResponse, error) {
 	}
 	return &result, nil
 }
+
+func (c *Client) GetMovieRecommendations(id int, page int) (*MultiSearchResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	url := fmt.Sprintf("%s/movie/%d/recommendations?page=%d&language=en-US", baseURL, id, page)
+
+	var result MultiSearchResponse
+	if err := c.doRequest(url, &result); err != nil {
+		return nil, err
+	}
+	c.resolveGenres(result.Results, "movie", "en-US")
+	return &result, nil
+}
+
+func (c *Client) GetSimilarMovies(id int, page int) (*MultiSearchResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	url := fmt.Sprintf("%s/movie/%d/similar?page=%d&language=en-US", baseURL, id, page)
+
+	var result MultiSearchResponse
+	if err := c.doRequest(url, &result); err != nil {
+		return nil, err
+	}
+	c.resolveGenres(result.Results, "movie", "en-US")
+	return &result, nil
+}
+
+func (c *Client) GetMovieVideos(id int) (*VideoResponse, error) {
+	url := fmt.Sprintf("%s/movie/%d/videos?language=en-US&include_video_language=en,null", baseURL, id)
+
+	var result VideoResponse
+	if err := c.doRequest(url, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,13 @@
+package tmdb
+
+import "fmt"
+
+func (c *Client) GetPersonDetails(id int) (*PersonDetails, error) {
+	url := fmt.Sprintf("%s/person/%d?append_to_response=combined_credits,external_ids&language=en-US", baseURL, id)
+
+	var result PersonDetails
+	if err := c.doRequest(url, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -12,5 +12,6 @@ func (c *Client) MultiSearch(query string, page int) (*MultiSearchResponse, erro
 	if err := c.doRequest(url, &result); err != nil {
 		return nil, err
 	}
+	c.resolveGenres(result.Results, "", "en-US")
 	return &result, nil
 }
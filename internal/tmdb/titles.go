@@ -0,0 +1,144 @@
+package tmdb
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// GetMovieTitles fetches a movie with its release dates, alternative
+// titles and translations appended: everything a torrent search needs.
+func (c *Client) GetMovieTitles(id int) (*MovieDetails, error) {
+	url := fmt.Sprintf("%s/movie/%d?append_to_response=release_dates,alternative_titles,translations&language=en-US", baseURL, id)
+
+	var result MovieDetails
+	if err := c.doRequest(url, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetTVTitles fetches a show with its external IDs, alternative titles
+// and translations appended.
+func (c *Client) GetTVTitles(id int) (*TVDetails, error) {
+	url := fmt.Sprintf("%s/tv/%d?append_to_response=external_ids,alternative_titles,translations&language=en-US", baseURL, id)
+
+	var result TVDetails
+	if err := c.doRequest(url, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// englishCountries are where English-language scene releases usually take
+// their titles from.
+var englishCountries = map[string]bool{"US": true, "GB": true, "CA": true, "AU": true}
+
+// sceneTitles ranks alternative titles and translations by how likely
+// they are to name a release: English and romanized titles first, then
+// titles used in English-speaking countries, then titles from the
+// country of origin. Titles matching one in exclude are skipped.
+func sceneTitles(alts *AlternativeTitles, tr *Translations, origin []string, exclude []string, limit int) []string {
+	type candidate struct {
+		title string
+		score int
+	}
+	var candidates []candidate
+	seen := make(map[string]bool)
+	for _, t := range exclude {
+		seen[strings.ToLower(t)] = true
+	}
+	add := func(title string, score int) {
+		key := strings.ToLower(strings.TrimSpace(title))
+		if key == "" || score == 0 || seen[key] {
+			return
+		}
+		seen[key] = true
+		candidates = append(candidates, candidate{title: title, score: score})
+	}
+
+	fromOrigin := make(map[string]bool, len(origin))
+	for _, c := range origin {
+		fromOrigin[c] = true
+	}
+
+	if tr != nil {
+		for _, t := range tr.Translations {
+			if t.ISO6391 == "en" {
+				add(t.Data.DisplayTitle(), 5)
+			}
+		}
+	}
+	if alts != nil {
+		for _, a := range alts.All() {
+			kind := strings.ToLower(a.Type)
+			score := 0
+			switch {
+			case strings.Contains(kind, "english"):
+				score = 5
+			case strings.Contains(kind, "romaniz") || strings.Contains(kind, "translit"):
+				score = 4
+			case englishCountries[a.ISO31661]:
+				score = 3
+			case fromOrigin[a.ISO31661]:
+				score = 2
+			}
+			add(a.Title, score)
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score > candidates[j].score
+	})
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	titles := make([]string, len(candidates))
+	for i, c := range candidates {
+		titles[i] = c.title
+	}
+	return titles
+}
+
+// SceneTitles returns up to limit alternative titles worth searching for,
+// best first, leaving out the title and original title.
+func (m MovieDetails) SceneTitles(limit int) []string {
+	return sceneTitles(m.AltTitles, m.Translations, m.OriginCountry, []string{m.Title, m.OriginalTitle}, limit)
+}
+
+// SceneTitles returns up to limit alternative names worth searching for,
+// best first, leaving out the name and original name.
+func (t TVDetails) SceneTitles(limit int) []string {
+	return sceneTitles(t.AltTitles, t.Translations, t.OriginCountry, []string{t.Name, t.OriginalName}, limit)
+}
+
+// AltTitlesResponse collects the movie's alternative titles and the
+// scene titles the magnet search would try.
+func (m MovieDetails) AltTitlesResponse(limit int) AltTitlesResponse {
+	return altTitlesResponse(m.ID, m.AltTitles, m.Translations, m.SceneTitles(limit))
+}
+
+// AltTitlesResponse collects the show's alternative names and the scene
+// names the magnet search would try.
+func (t TVDetails) AltTitlesResponse(limit int) AltTitlesResponse {
+	return altTitlesResponse(t.ID, t.AltTitles, t.Translations, t.SceneTitles(limit))
+}
+
+func altTitlesResponse(id int, alts *AlternativeTitles, tr *Translations, scene []string) AltTitlesResponse {
+	resp := AltTitlesResponse{
+		ID:           id,
+		Titles:       []AlternativeTitle{},
+		Translations: []Translation{},
+		SceneTitles:  scene,
+	}
+	if alts != nil {
+		resp.Titles = alts.All()
+	}
+	if tr != nil && tr.Translations != nil {
+		resp.Translations = tr.Translations
+	}
+	if resp.SceneTitles == nil {
+		resp.SceneTitles = []string{}
+	}
+	return resp
+}
@@ -12,6 +12,7 @@ func (c *Client) GetTrendingMovies(timeWindow string) (*MultiSearchResponse, err
 	if err := c.doRequest(url, &result); err != nil {
 		return nil, err
 	}
+	c.resolveGenres(result.Results, "movie", "en-US")
 	return &result, nil
 }
 
@@ -25,5 +26,6 @@ func (c *Client) GetTrendingTV(timeWindow string) (*MultiSearchResponse, error)
 	if err := c.doRequest(url, &result); err != nil {
 		return nil, err
 	}
+	c.resolveGenres(result.Results, "tv", "en-US")
 	return &result, nil
 }
@@ -3,7 +3,19 @@ package tmdb
 import "fmt"
 
 func (c *Client) GetTVDetails(id int) (*TVDetails, error) {
-	url := fmt.Sprintf("%s/tv/%d?append_to_response=credits&language=en-US", baseURL, id)
+	url := fmt.Sprintf("%s/tv/%d?append_to_response=credits,recommendations,similar,watch/providers,videos,content_ratings,external_ids&language=en-US&include_video_language=en,null", baseURL, id)
+
+	var result TVDetails
+	if err := c.doRequest(url, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetTVSummary fetches a show without appended credits, videos or lists,
+// for callers that only need its status and episode air dates.
+func (c *Client) GetTVSummary(id int) (*TVDetails, error) {
+	url := fmt.Sprintf("%s/tv/%d?language=en-US", baseURL, id)
 
 	var result TVDetails
 	if err := c.doRequest(url, &result); err != nil {
@@ -34,3 +46,41 @@ func (c *Client) GetTVReviews(id int, page int) (*ReviewResponse, error) {
 	}
 	return &result, nil
 }
+
+func (c *Client) GetTVRecommendations(id int, page int) (*MultiSearchResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	url := fmt.Sprintf("%s/tv/%d/recommendations?page=%d&language=en-US", baseURL, id, page)
+
+	var result MultiSearchResponse
+	if err := c.doRequest(url, &result); err != nil {
+		return nil, err
+	}
+	c.resolveGenres(result.Results, "tv", "en-US")
+	return &result, nil
+}
+
+func (c *Client) GetSimilarTV(id int, page int) (*MultiSearchResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	url := fmt.Sprintf("%s/tv/%d/similar?page=%d&language=en-US", baseURL, id, page)
+
+	var result MultiSearchResponse
+	if err := c.doRequest(url, &result); err != nil {
+		return nil, err
+	}
+	c.resolveGenres(result.Results, "tv", "en-US")
+	return &result, nil
+}
+
+func (c *Client) GetTVVideos(id int) (*VideoResponse, error) {
+	url := fmt.Sprintf("%s/tv/%d/videos?language=en-US&include_video_language=en,null", baseURL, id)
+
+	var result VideoResponse
+	if err := c.doRequest(url, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -1,34 +1,48 @@
 package tmdb
 
-import "fmt"
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
 
 const (
 	ImageBaseURL = "https://image.tmdb.org/t/p"
 )
 
 type MultiSearchResponse struct {
-	Page            int                `json:"page"`
-	Results         []SearchResult     `json:"results"`
-	TotalPages     int                `json:"total_pages"`
-	TotalResults   int                `json:"total_results"`
+	Page         int            `json:"page"`
+	Results      []SearchResult `json:"results"`
+	TotalPages   int            `json:"total_pages"`
+	TotalResults int            `json:"total_results"`
+	Dates        *DateRange     `json:"dates,omitempty"`
+}
+
+// DateRange is the release window TMDB reports for upcoming and
+// now-playing lists.
+type DateRange struct {
+	Minimum string `json:"minimum"`
+	Maximum string `json:"maximum"`
 }
 
 type SearchResult struct {
-	ID            int      `json:"id"`
-	MediaType     string   `json:"media_type"`
-	Title         string   `json:"title"`
-	Name          string   `json:"name"`
-	Overview      string   `json:"overview"`
-	PosterPath    string   `json:"poster_path"`
-	BackdropPath  string   `json:"backdrop_path"`
-	ReleaseDate   string   `json:"release_date"`
-	FirstAirDate  string   `json:"first_air_date"`
-	VoteAverage   float64  `json:"vote_average"`
-	VoteCount     int      `json:"vote_count"`
-	GenreIDs      []int    `json:"genre_ids"`
-	Popularity    float64  `json:"popularity"`
-	OriginalLanguage string `json:"original_language"`
-	Adult         bool     `json:"adult"`
+	ID               int      `json:"id"`
+	MediaType        string   `json:"media_type"`
+	Title            string   `json:"title"`
+	Name             string   `json:"name"`
+	Overview         string   `json:"overview"`
+	PosterPath       string   `json:"poster_path"`
+	BackdropPath     string   `json:"backdrop_path"`
+	ReleaseDate      string   `json:"release_date"`
+	FirstAirDate     string   `json:"first_air_date"`
+	VoteAverage      float64  `json:"vote_average"`
+	VoteCount        int      `json:"vote_count"`
+	GenreIDs         []int    `json:"genre_ids"`
+	Popularity       float64  `json:"popularity"`
+	OriginalLanguage string   `json:"original_language"`
+	Adult            bool     `json:"adult"`
+	GenreNames       []string `json:"genre_names,omitempty"`
 }
 
 func (s SearchResult) DisplayTitle() string {
@@ -66,9 +80,9 @@ func (s SearchResult) BackdropURL(size string) string {
 }
 
 type ReviewResponse struct {
-	ID            int      `json:"id"`
-	Page          int      `json:"page"`
-	Results       []Review `json:"results"`
+	ID           int      `json:"id"`
+	Page         int      `json:"page"`
+	Results      []Review `json:"results"`
 	TotalPages   int      `json:"total_pages"`
 	TotalResults int      `json:"total_results"`
 }
@@ -83,10 +97,10 @@ type Review struct {
 }
 
 type AuthorDetails struct {
-	Name        string   `json:"name"`
-	Username    string   `json:"username"`
-	AvatarPath  string   `json:"avatar_path"`
-	Rating      *float64 `json:"rating"`
+	Name       string   `json:"name"`
+	Username   string   `json:"username"`
+	AvatarPath string   `json:"avatar_path"`
+	Rating     *float64 `json:"rating"`
 }
 
 func (a AuthorDetails) AvatarURL() string {
@@ -111,29 +125,38 @@ func (r Review) RatingDisplay() string {
 }
 
 type MovieDetails struct {
-	Adult               bool            `json:"adult"`
-	BackdropPath        string          `json:"backdrop_path"`
-	Budget              int64           `json:"budget"`
-	Genres              []Genre         `json:"genres"`
-	Homepage            string          `json:"homepage"`
-	ID                  int             `json:"id"`
-	IMDbID              string          `json:"imdb_id"`
-	OriginalLanguage    string          `json:"original_language"`
-	OriginalTitle       string          `json:"original_title"`
-	Overview            string          `json:"overview"`
-	Popularity          float64         `json:"popularity"`
-	PosterPath          string          `json:"poster_path"`
-	ProductionCompanies []Company       `json:"production_companies"`
-	ReleaseDate         string          `json:"release_date"`
-	Revenue             int64           `json:"revenue"`
-	Runtime             int             `json:"runtime"`
-	Status              string          `json:"status"`
-	Tagline             string          `json:"tagline"`
-	Title               string          `json:"title"`
-	VoteAverage         float64         `json:"vote_average"`
-	VoteCount           int             `json:"vote_count"`
-	Credits             *Credits        `json:"credits,omitempty"`
-	Reviews             *ReviewResponse `json:"reviews,omitempty"`
+	Adult               bool                    `json:"adult"`
+	BackdropPath        string                  `json:"backdrop_path"`
+	Budget              int64                   `json:"budget"`
+	Genres              []Genre                 `json:"genres"`
+	Homepage            string                  `json:"homepage"`
+	ID                  int                     `json:"id"`
+	IMDbID              string                  `json:"imdb_id"`
+	OriginalLanguage    string                  `json:"original_language"`
+	OriginalTitle       string                  `json:"original_title"`
+	Overview            string                  `json:"overview"`
+	Popularity          float64                 `json:"popularity"`
+	PosterPath          string                  `json:"poster_path"`
+	ProductionCompanies []Company               `json:"production_companies"`
+	ReleaseDate         string                  `json:"release_date"`
+	Revenue             int64                   `json:"revenue"`
+	Runtime             int                     `json:"runtime"`
+	Status              string                  `json:"status"`
+	Tagline             string                  `json:"tagline"`
+	Title               string                  `json:"title"`
+	VoteAverage         float64                 `json:"vote_average"`
+	VoteCount           int                     `json:"vote_count"`
+	Credits             *Credits                `json:"credits,omitempty"`
+	Reviews             *ReviewResponse         `json:"reviews,omitempty"`
+	Recommendations     *MultiSearchResponse    `json:"recommendations,omitempty"`
+	Similar             *MultiSearchResponse    `json:"similar,omitempty"`
+	BelongsToCollection *CollectionSummary      `json:"belongs_to_collection"`
+	WatchProviders      *WatchProvidersResponse `json:"watch/providers,omitempty"`
+	Videos              *VideoResponse          `json:"videos,omitempty"`
+	ReleaseDates        *ReleaseDatesResponse   `json:"release_dates,omitempty"`
+	OriginCountry       []string                `json:"origin_country,omitempty"`
+	AltTitles           *AlternativeTitles      `json:"alternative_titles,omitempty"`
+	Translations        *Translations           `json:"translations,omitempty"`
 }
 
 func (m MovieDetails) PosterURL(size string) string {
@@ -156,33 +179,97 @@ func (m MovieDetails) BackdropURL(size string) string {
 	return ImageBaseURL + "/" + size + m.BackdropPath
 }
 
+type CollectionSummary struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	PosterPath   string `json:"poster_path"`
+	BackdropPath string `json:"backdrop_path"`
+}
+
+type Collection struct {
+	ID           int            `json:"id"`
+	Name         string         `json:"name"`
+	Overview     string         `json:"overview"`
+	PosterPath   string         `json:"poster_path"`
+	BackdropPath string         `json:"backdrop_path"`
+	Parts        []SearchResult `json:"parts"`
+}
+
+// SortParts orders the collection's films by release date, oldest first.
+// Unreleased parts without a date go last.
+func (c *Collection) SortParts() {
+	sort.SliceStable(c.Parts, func(i, j int) bool {
+		a, b := c.Parts[i].ReleaseDate, c.Parts[j].ReleaseDate
+		if a == "" || b == "" {
+			return a != ""
+		}
+		return a < b
+	})
+}
+
+// BaseName strips TMDB's trailing " Collection" so the franchise name can be
+// used in torrent pack queries.
+func (c Collection) BaseName() string {
+	return strings.TrimSuffix(c.Name, " Collection")
+}
+
+func (c Collection) PosterURL(size string) string {
+	if c.PosterPath == "" {
+		return ""
+	}
+	if size == "" {
+		size = "w500"
+	}
+	return ImageBaseURL + "/" + size + c.PosterPath
+}
+
+func (c Collection) BackdropURL(size string) string {
+	if c.BackdropPath == "" {
+		return ""
+	}
+	if size == "" {
+		size = "w1280"
+	}
+	return ImageBaseURL + "/" + size + c.BackdropPath
+}
+
 type TVDetails struct {
-	BackdropPath      string          `json:"backdrop_path"`
-	EpisodeRunTime    []int           `json:"episode_run_time"`
-	FirstAirDate      string          `json:"first_air_date"`
-	Genres            []Genre         `json:"genres"`
-	Homepage          string          `json:"homepage"`
-	ID                int             `json:"id"`
-	InProduction      bool            `json:"in_production"`
-	Languages         []string        `json:"languages"`
-	LastAirDate       string          `json:"last_air_date"`
-	Name              string          `json:"name"`
-	Networks          []Network       `json:"networks"`
-	NumberOfEpisodes  int             `json:"number_of_episodes"`
-	NumberOfSeasons   int             `json:"number_of_seasons"`
-	OriginCountry     []string        `json:"origin_country"`
-	OriginalLanguage  string          `json:"original_language"`
-	OriginalName      string          `json:"original_name"`
-	Overview          string          `json:"overview"`
-	Popularity        float64         `json:"popularity"`
-	PosterPath        string          `json:"poster_path"`
-	Seasons           []Season        `json:"seasons"`
-	Status            string          `json:"status"`
-	Tagline           string          `json:"tagline"`
-	Type              string          `json:"type"`
-	VoteAverage       float64         `json:"vote_average"`
-	VoteCount         int             `json:"vote_count"`
-	Credits           *Credits        `json:"credits,omitempty"`
+	BackdropPath     string                  `json:"backdrop_path"`
+	EpisodeRunTime   []int                   `json:"episode_run_time"`
+	FirstAirDate     string                  `json:"first_air_date"`
+	Genres           []Genre                 `json:"genres"`
+	Homepage         string                  `json:"homepage"`
+	ID               int                     `json:"id"`
+	InProduction     bool                    `json:"in_production"`
+	Languages        []string                `json:"languages"`
+	LastAirDate      string                  `json:"last_air_date"`
+	Name             string                  `json:"name"`
+	Networks         []Network               `json:"networks"`
+	NumberOfEpisodes int                     `json:"number_of_episodes"`
+	NumberOfSeasons  int                     `json:"number_of_seasons"`
+	OriginCountry    []string                `json:"origin_country"`
+	OriginalLanguage string                  `json:"original_language"`
+	OriginalName     string                  `json:"original_name"`
+	Overview         string                  `json:"overview"`
+	Popularity       float64                 `json:"popularity"`
+	PosterPath       string                  `json:"poster_path"`
+	Seasons          []Season                `json:"seasons"`
+	Status           string                  `json:"status"`
+	Tagline          string                  `json:"tagline"`
+	Type             string                  `json:"type"`
+	VoteAverage      float64                 `json:"vote_average"`
+	VoteCount        int                     `json:"vote_count"`
+	LastEpisodeToAir *Episode                `json:"last_episode_to_air,omitempty"`
+	NextEpisodeToAir *Episode                `json:"next_episode_to_air,omitempty"`
+	Credits          *Credits                `json:"credits,omitempty"`
+	Recommendations  *MultiSearchResponse    `json:"recommendations,omitempty"`
+	Similar          *MultiSearchResponse    `json:"similar,omitempty"`
+	WatchProviders   *WatchProvidersResponse `json:"watch/providers,omitempty"`
+	Videos           *VideoResponse          `json:"videos,omitempty"`
+	ContentRatings   *ContentRatingsResponse `json:"content_ratings,omitempty"`
+	ExternalIDs      *ExternalIDs            `json:"external_ids,omitempty"`
+	AltTitles        *AlternativeTitles      `json:"alternative_titles,omitempty"`
+	Translations     *Translations           `json:"translations,omitempty"`
 }
 
 func (t TVDetails) PosterURL(size string) string {
@@ -195,6 +282,50 @@ func (t TVDetails) PosterURL(size string) string {
 	return ImageBaseURL + "/" + size + t.PosterPath
 }
 
+// AbsoluteNumber is an episode's position counting every regular season
+// before it, as anime releases number episodes. It is 0 for specials.
+func (t TVDetails) AbsoluteNumber(season, episode int) int {
+	if season < 1 {
+		return 0
+	}
+	n := episode
+	for _, s := range t.Seasons {
+		if s.SeasonNumber > 0 && s.SeasonNumber < season {
+			n += s.EpisodeCount
+		}
+	}
+	return n
+}
+
+// IsAnime reports whether the show is Japanese animation, whose releases
+// use fansub naming and absolute episode numbers.
+func (t TVDetails) IsAnime() bool {
+	animated := false
+	for _, g := range t.Genres {
+		if g.ID == animationGenreID {
+			animated = true
+		}
+	}
+	if !animated {
+		return false
+	}
+	if t.OriginalLanguage == "ja" {
+		return true
+	}
+	for _, c := range t.OriginCountry {
+		if c == "JP" {
+			return true
+		}
+	}
+	return false
+}
+
+// IsDaily reports whether the show is a talk or news show, whose releases
+// are named by air date rather than episode number.
+func (t TVDetails) IsDaily() bool {
+	return t.Type == "Talk Show" || t.Type == "News"
+}
+
 func (t TVDetails) BackdropURL(size string) string {
 	if t.BackdropPath == "" {
 		return ""
@@ -246,16 +377,16 @@ func (s SeasonDetails) PosterURL(size string) string {
 }
 
 type Episode struct {
-	ID               int     `json:"id"`
-	Name             string  `json:"name"`
-	Overview         string  `json:"overview"`
-	AirDate          string  `json:"air_date"`
-	EpisodeNumber    int     `json:"episode_number"`
-	SeasonNumber     int     `json:"season_number"`
-	StillPath        string  `json:"still_path"`
-	VoteAverage      float64 `json:"vote_average"`
-	VoteCount        int     `json:"vote_count"`
-	Runtime          int     `json:"runtime"`
+	ID            int     `json:"id"`
+	Name          string  `json:"name"`
+	Overview      string  `json:"overview"`
+	AirDate       string  `json:"air_date"`
+	EpisodeNumber int     `json:"episode_number"`
+	SeasonNumber  int     `json:"season_number"`
+	StillPath     string  `json:"still_path"`
+	VoteAverage   float64 `json:"vote_average"`
+	VoteCount     int     `json:"vote_count"`
+	Runtime       int     `json:"runtime"`
 }
 
 func (e Episode) StillURL(size string) string {
@@ -321,3 +452,805 @@ type CrewMember struct {
 	Job         string `json:"job"`
 	ProfilePath string `json:"profile_path"`
 }
+
+const (
+	FilmographySortYear       = "year"
+	FilmographySortPopularity = "popularity"
+)
+
+type PersonDetails struct {
+	ID                 int              `json:"id"`
+	Name               string           `json:"name"`
+	AlsoKnownAs        []string         `json:"also_known_as"`
+	Biography          string           `json:"biography"`
+	Birthday           string           `json:"birthday"`
+	Deathday           string           `json:"deathday"`
+	PlaceOfBirth       string           `json:"place_of_birth"`
+	Homepage           string           `json:"homepage"`
+	IMDbID             string           `json:"imdb_id"`
+	KnownForDepartment string           `json:"known_for_department"`
+	Popularity         float64          `json:"popularity"`
+	ProfilePath        string           `json:"profile_path"`
+	CombinedCredits    *CombinedCredits `json:"combined_credits,omitempty"`
+	ExternalIDs        *ExternalIDs     `json:"external_ids,omitempty"`
+}
+
+func (p PersonDetails) ProfileURL(size string) string {
+	if p.ProfilePath == "" {
+		return ""
+	}
+	if size == "" {
+		size = "h632"
+	}
+	return ImageBaseURL + "/" + size + p.ProfilePath
+}
+
+// Filmography merges cast and crew credits into one entry per title, so a
+// director who also acted in a film is listed once with both roles.
+// Entries are ordered newest first for FilmographySortYear, and by TMDB
+// popularity for FilmographySortPopularity.
+func (p PersonDetails) Filmography(sortBy string) []FilmographyEntry {
+	if p.CombinedCredits == nil {
+		return nil
+	}
+
+	var entries []FilmographyEntry
+	index := make(map[string]int)
+
+	add := func(c PersonCredit, role string) {
+		key := fmt.Sprintf("%s-%d", c.MediaType, c.ID)
+		idx, ok := index[key]
+		if !ok {
+			idx = len(entries)
+			index[key] = idx
+			entries = append(entries, FilmographyEntry{
+				ID:          c.ID,
+				MediaType:   c.MediaType,
+				Title:       c.DisplayTitle(),
+				Date:        c.DisplayDate(),
+				PosterPath:  c.PosterPath,
+				VoteAverage: c.VoteAverage,
+				VoteCount:   c.VoteCount,
+				Popularity:  c.Popularity,
+				Adult:       c.Adult,
+			})
+		}
+		if role == "" {
+			return
+		}
+		for _, r := range entries[idx].Roles {
+			if r == role {
+				return
+			}
+		}
+		entries[idx].Roles = append(entries[idx].Roles, role)
+	}
+
+	for _, c := range p.CombinedCredits.Cast {
+		add(c, c.Character)
+	}
+	for _, c := range p.CombinedCredits.Crew {
+		add(c, c.Job)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if sortBy == FilmographySortPopularity {
+			return entries[i].Popularity > entries[j].Popularity
+		}
+		if entries[i].Date == "" || entries[j].Date == "" {
+			return entries[i].Date != ""
+		}
+		return entries[i].Date > entries[j].Date
+	})
+
+	return entries
+}
+
+type CombinedCredits struct {
+	Cast []PersonCredit `json:"cast"`
+	Crew []PersonCredit `json:"crew"`
+}
+
+type PersonCredit struct {
+	ID           int     `json:"id"`
+	MediaType    string  `json:"media_type"`
+	Title        string  `json:"title"`
+	Name         string  `json:"name"`
+	Character    string  `json:"character"`
+	Job          string  `json:"job"`
+	Department   string  `json:"department"`
+	EpisodeCount int     `json:"episode_count"`
+	ReleaseDate  string  `json:"release_date"`
+	FirstAirDate string  `json:"first_air_date"`
+	PosterPath   string  `json:"poster_path"`
+	VoteAverage  float64 `json:"vote_average"`
+	VoteCount    int     `json:"vote_count"`
+	Popularity   float64 `json:"popularity"`
+	Adult        bool    `json:"adult"`
+}
+
+func (c PersonCredit) DisplayTitle() string {
+	if c.Title != "" {
+		return c.Title
+	}
+	return c.Name
+}
+
+func (c PersonCredit) DisplayDate() string {
+	if c.ReleaseDate != "" {
+		return c.ReleaseDate
+	}
+	return c.FirstAirDate
+}
+
+type FilmographyEntry struct {
+	ID          int      `json:"id"`
+	MediaType   string   `json:"media_type"`
+	Title       string   `json:"title"`
+	Date        string   `json:"date"`
+	PosterPath  string   `json:"poster_path"`
+	VoteAverage float64  `json:"vote_average"`
+	VoteCount   int      `json:"vote_count"`
+	Popularity  float64  `json:"popularity"`
+	Adult       bool     `json:"adult"`
+	Roles       []string `json:"roles"`
+}
+
+func (e FilmographyEntry) Year() string {
+	if len(e.Date) < 4 {
+		return ""
+	}
+	return e.Date[:4]
+}
+
+func (e FilmographyEntry) PosterURL(size string) string {
+	if e.PosterPath == "" {
+		return ""
+	}
+	if size == "" {
+		size = "w342"
+	}
+	return ImageBaseURL + "/" + size + e.PosterPath
+}
+
+type ExternalIDs struct {
+	IMDbID      string `json:"imdb_id"`
+	TVDBID      int    `json:"tvdb_id,omitempty"`
+	WikidataID  string `json:"wikidata_id"`
+	FacebookID  string `json:"facebook_id"`
+	InstagramID string `json:"instagram_id"`
+	TwitterID   string `json:"twitter_id"`
+	TikTokID    string `json:"tiktok_id"`
+	YoutubeID   string `json:"youtube_id"`
+}
+
+// Offer types reported by TMDB's watch/providers endpoints.
+const (
+	OfferFlatrate = "flatrate"
+	OfferFree     = "free"
+	OfferAds      = "ads"
+	OfferRent     = "rent"
+	OfferBuy      = "buy"
+)
+
+type WatchProvidersResponse struct {
+	ID      int                        `json:"id"`
+	Results map[string]RegionProviders `json:"results"`
+}
+
+type RegionProviders struct {
+	Link     string     `json:"link"`
+	Flatrate []Provider `json:"flatrate,omitempty"`
+	Free     []Provider `json:"free,omitempty"`
+	Ads      []Provider `json:"ads,omitempty"`
+	Rent     []Provider `json:"rent,omitempty"`
+	Buy      []Provider `json:"buy,omitempty"`
+}
+
+type Provider struct {
+	ProviderID      int    `json:"provider_id"`
+	ProviderName    string `json:"provider_name"`
+	LogoPath        string `json:"logo_path"`
+	DisplayPriority int    `json:"display_priority"`
+}
+
+func (p Provider) LogoURL(size string) string {
+	if p.LogoPath == "" {
+		return ""
+	}
+	if size == "" {
+		size = "w92"
+	}
+	return ImageBaseURL + "/" + size + p.LogoPath
+}
+
+type WatchRegion struct {
+	ISO31661    string `json:"iso_3166_1"`
+	EnglishName string `json:"english_name"`
+	NativeName  string `json:"native_name"`
+}
+
+// Availability is the legal offer of a title in one region, one entry per
+// provider with every way it can be watched there.
+type Availability struct {
+	Region string          `json:"region"`
+	Link   string          `json:"link,omitempty"`
+	Offers []ProviderOffer `json:"offers"`
+}
+
+type ProviderOffer struct {
+	Provider
+	Types      []string `json:"types"`
+	Subscribed bool     `json:"subscribed"`
+}
+
+// Streamable reports whether a subscribed provider includes the title
+// without extra payment.
+func (a Availability) Streamable() bool {
+	for _, o := range a.Offers {
+		if o.Subscribed && o.Has(OfferFlatrate) {
+			return true
+		}
+	}
+	return false
+}
+
+func (o ProviderOffer) Has(offerType string) bool {
+	for _, t := range o.Types {
+		if t == offerType {
+			return true
+		}
+	}
+	return false
+}
+
+// Availability builds the offers for region. Subscribed providers come
+// first, then providers that stream the title, each group in TMDB's
+// display order. It returns nil when the title is not offered in region.
+func (w WatchProvidersResponse) Availability(region string, subscribed map[int]bool) *Availability {
+	rp, ok := w.Results[region]
+	if !ok {
+		return nil
+	}
+
+	var offers []ProviderOffer
+	index := make(map[int]int)
+	add := func(providers []Provider, offerType string) {
+		for _, p := range providers {
+			idx, ok := index[p.ProviderID]
+			if !ok {
+				idx = len(offers)
+				index[p.ProviderID] = idx
+				offers = append(offers, ProviderOffer{Provider: p, Subscribed: subscribed[p.ProviderID]})
+			}
+			offers[idx].Types = append(offers[idx].Types, offerType)
+		}
+	}
+	add(rp.Flatrate, OfferFlatrate)
+	add(rp.Free, OfferFree)
+	add(rp.Ads, OfferAds)
+	add(rp.Rent, OfferRent)
+	add(rp.Buy, OfferBuy)
+
+	sort.SliceStable(offers, func(i, j int) bool {
+		if offers[i].Subscribed != offers[j].Subscribed {
+			return offers[i].Subscribed
+		}
+		si, sj := offers[i].Has(OfferFlatrate), offers[j].Has(OfferFlatrate)
+		if si != sj {
+			return si
+		}
+		return offers[i].DisplayPriority < offers[j].DisplayPriority
+	})
+
+	return &Availability{Region: region, Link: rp.Link, Offers: offers}
+}
+
+// videoTypeRank orders TMDB video types by how useful they are as the
+// title's main trailer. Types not listed rank last.
+var videoTypeRank = map[string]int{
+	"Trailer":           0,
+	"Teaser":            1,
+	"Clip":              2,
+	"Featurette":        3,
+	"Opening Credits":   4,
+	"Behind the Scenes": 5,
+	"Bloopers":          6,
+}
+
+type VideoResponse struct {
+	ID      int     `json:"id"`
+	Results []Video `json:"results"`
+}
+
+type Video struct {
+	ID          string `json:"id"`
+	Key         string `json:"key"`
+	Name        string `json:"name"`
+	Site        string `json:"site"`
+	Type        string `json:"type"`
+	Official    bool   `json:"official"`
+	Language    string `json:"iso_639_1"`
+	Region      string `json:"iso_3166_1"`
+	Size        int    `json:"size"`
+	PublishedAt string `json:"published_at"`
+}
+
+// EmbedURL returns the player URL for YouTube and Vimeo videos, or "" for
+// sites that cannot be embedded.
+func (v Video) EmbedURL() string {
+	switch v.Site {
+	case "YouTube":
+		return "https://www.youtube-nocookie.com/embed/" + v.Key
+	case "Vimeo":
+		return "https://player.vimeo.com/video/" + v.Key
+	}
+	return ""
+}
+
+func (v Video) ThumbnailURL() string {
+	if v.Site == "YouTube" {
+		return "https://img.youtube.com/vi/" + v.Key + "/hqdefault.jpg"
+	}
+	return ""
+}
+
+// Ranked returns the embeddable videos, trailers first, then teasers and
+// other extras. Within a type, official videos come first, then English
+// ones, then the most recently published.
+func (r VideoResponse) Ranked() []Video {
+	var videos []Video
+	for _, v := range r.Results {
+		if v.EmbedURL() != "" {
+			videos = append(videos, v)
+		}
+	}
+
+	rank := func(t string) int {
+		if n, ok := videoTypeRank[t]; ok {
+			return n
+		}
+		return len(videoTypeRank)
+	}
+
+	sort.SliceStable(videos, func(i, j int) bool {
+		a, b := videos[i], videos[j]
+		if rank(a.Type) != rank(b.Type) {
+			return rank(a.Type) < rank(b.Type)
+		}
+		if a.Official != b.Official {
+			return a.Official
+		}
+		if (a.Language == "en") != (b.Language == "en") {
+			return a.Language == "en"
+		}
+		return a.PublishedAt > b.PublishedAt
+	})
+	return videos
+}
+
+// Trailer returns the best ranked video, or nil when none can be embedded.
+func (r VideoResponse) Trailer() *Video {
+	videos := r.Ranked()
+	if len(videos) == 0 {
+		return nil
+	}
+	return &videos[0]
+}
+
+// CalendarEntry is a single movie release or episode air date.
+type CalendarEntry struct {
+	Date          string `json:"date"`
+	MediaType     string `json:"media_type"`
+	ID            int    `json:"id"`
+	Title         string `json:"title"`
+	PosterPath    string `json:"poster_path"`
+	Overview      string `json:"overview"`
+	SeasonNumber  int    `json:"season_number,omitempty"`
+	EpisodeNumber int    `json:"episode_number,omitempty"`
+	EpisodeName   string `json:"episode_name,omitempty"`
+}
+
+// CalendarDay holds every entry released or airing on one date.
+type CalendarDay struct {
+	Date    string          `json:"date"`
+	Entries []CalendarEntry `json:"entries"`
+}
+
+// Label formats the day's date for display, e.g. "Monday, January 2".
+func (d CalendarDay) Label() string {
+	t, err := time.Parse("2006-01-02", d.Date)
+	if err != nil {
+		return d.Date
+	}
+	return t.Format("Monday, January 2")
+}
+
+// GroupByDate buckets entries into days in date order. Entries on the
+// same day keep movies first, then titles alphabetically.
+func GroupByDate(entries []CalendarEntry) []CalendarDay {
+	sorted := make([]CalendarEntry, len(entries))
+	copy(sorted, entries)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.Date != b.Date {
+			return a.Date < b.Date
+		}
+		if a.MediaType != b.MediaType {
+			return a.MediaType == "movie"
+		}
+		return a.Title < b.Title
+	})
+
+	var days []CalendarDay
+	for _, e := range sorted {
+		if len(days) == 0 || days[len(days)-1].Date != e.Date {
+			days = append(days, CalendarDay{Date: e.Date})
+		}
+		days[len(days)-1].Entries = append(days[len(days)-1].Entries, e)
+	}
+	return days
+}
+
+// ReleaseType is TMDB's release type code.
+type ReleaseType int
+
+const (
+	ReleasePremiere ReleaseType = iota + 1
+	ReleaseTheatricalLimited
+	ReleaseTheatrical
+	ReleaseDigital
+	ReleasePhysical
+	ReleaseTV
+)
+
+func (t ReleaseType) String() string {
+	switch t {
+	case ReleasePremiere:
+		return "Premiere"
+	case ReleaseTheatricalLimited:
+		return "Limited Theatrical"
+	case ReleaseTheatrical:
+		return "Theatrical"
+	case ReleaseDigital:
+		return "Digital"
+	case ReleasePhysical:
+		return "Physical"
+	case ReleaseTV:
+		return "TV"
+	}
+	return "Release"
+}
+
+type ReleaseDatesResponse struct {
+	ID      int               `json:"id"`
+	Results []CountryReleases `json:"results"`
+}
+
+type CountryReleases struct {
+	ISO31661     string        `json:"iso_3166_1"`
+	ReleaseDates []ReleaseDate `json:"release_dates"`
+}
+
+type ReleaseDate struct {
+	Certification string      `json:"certification"`
+	Descriptors   []string    `json:"descriptors"`
+	ISO6391       string      `json:"iso_639_1"`
+	Note          string      `json:"note"`
+	ReleaseDate   string      `json:"release_date"`
+	Type          ReleaseType `json:"type"`
+}
+
+// Date returns the release day as YYYY-MM-DD.
+func (r ReleaseDate) Date() string {
+	if len(r.ReleaseDate) < 10 {
+		return r.ReleaseDate
+	}
+	return r.ReleaseDate[:10]
+}
+
+// Region returns a country's release dates in date order, or nil.
+func (r ReleaseDatesResponse) Region(region string) []ReleaseDate {
+	for _, c := range r.Results {
+		if c.ISO31661 == region {
+			dates := make([]ReleaseDate, len(c.ReleaseDates))
+			copy(dates, c.ReleaseDates)
+			sort.SliceStable(dates, func(i, j int) bool {
+				return dates[i].ReleaseDate < dates[j].ReleaseDate
+			})
+			return dates
+		}
+	}
+	return nil
+}
+
+// digitalWindow is the typical gap between a theatrical and a digital
+// release, used to estimate digital dates TMDB does not know yet.
+const digitalWindow = 45 * 24 * time.Hour
+
+const (
+	ReleaseStatusUpcoming   = "upcoming"
+	ReleaseStatusTheatrical = "theatrical"
+	ReleaseStatusDigital    = "digital"
+)
+
+// ReleaseSchedule summarises when a movie reaches theatres, digital stores
+// and disc in one region, and whether a digital copy can exist anywhere yet.
+type ReleaseSchedule struct {
+	Region     string `json:"region"`
+	Theatrical string `json:"theatrical,omitempty"`
+	Digital    string `json:"digital,omitempty"`
+	Physical   string `json:"physical,omitempty"`
+	// ExpectedDigital is the first digital or physical release in any
+	// region, since that is when retail-sourced copies appear. When TMDB
+	// has none it is estimated from the first theatrical release.
+	ExpectedDigital string `json:"expected_digital,omitempty"`
+	Estimated       bool   `json:"estimated,omitempty"`
+	Status          string `json:"status"`
+}
+
+// Schedule works out the release schedule for region as of now.
+func (r ReleaseDatesResponse) Schedule(region string, now time.Time) *ReleaseSchedule {
+	return r.schedule(region, "", now)
+}
+
+// Schedule is like ReleaseDatesResponse.Schedule but falls back to the
+// primary release date when TMDB lists no theatrical release.
+func (m MovieDetails) Schedule(region string, now time.Time) *ReleaseSchedule {
+	var r ReleaseDatesResponse
+	if m.ReleaseDates != nil {
+		r = *m.ReleaseDates
+	}
+	return r.schedule(region, m.ReleaseDate, now)
+}
+
+func (r ReleaseDatesResponse) schedule(region, primary string, now time.Time) *ReleaseSchedule {
+	s := &ReleaseSchedule{Region: region}
+	for _, rd := range r.Region(region) {
+		date := rd.Date()
+		switch rd.Type {
+		case ReleaseTheatricalLimited, ReleaseTheatrical:
+			if s.Theatrical == "" {
+				s.Theatrical = date
+			}
+		case ReleaseDigital:
+			if s.Digital == "" {
+				s.Digital = date
+			}
+		case ReleasePhysical:
+			if s.Physical == "" {
+				s.Physical = date
+			}
+		}
+	}
+
+	var firstTheatrical string
+	for _, c := range r.Results {
+		for _, rd := range c.ReleaseDates {
+			date := rd.Date()
+			switch rd.Type {
+			case ReleaseTheatricalLimited, ReleaseTheatrical:
+				if firstTheatrical == "" || date < firstTheatrical {
+					firstTheatrical = date
+				}
+			case ReleaseDigital, ReleasePhysical:
+				if s.ExpectedDigital == "" || date < s.ExpectedDigital {
+					s.ExpectedDigital = date
+				}
+			}
+		}
+	}
+	if firstTheatrical == "" {
+		firstTheatrical = primary
+	}
+	if s.ExpectedDigital == "" && firstTheatrical != "" {
+		if t, err := time.Parse("2006-01-02", firstTheatrical); err == nil {
+			s.ExpectedDigital = t.Add(digitalWindow).Format("2006-01-02")
+			s.Estimated = true
+		}
+	}
+
+	today := now.Format("2006-01-02")
+	switch {
+	case s.ExpectedDigital != "" && s.ExpectedDigital <= today:
+		s.Status = ReleaseStatusDigital
+	case firstTheatrical != "" && firstTheatrical <= today:
+		s.Status = ReleaseStatusTheatrical
+	default:
+		s.Status = ReleaseStatusUpcoming
+	}
+	return s
+}
+
+// PreReleaseOnly reports whether only theatre-sourced copies (CAM,
+// telesync and the like) can exist yet.
+func (s ReleaseSchedule) PreReleaseOnly() bool {
+	return s.Status != ReleaseStatusDigital
+}
+
+// Message describes the digital release for display, or "" once a
+// digital release is out.
+func (s ReleaseSchedule) Message() string {
+	if !s.PreReleaseOnly() {
+		return ""
+	}
+	if s.ExpectedDigital == "" {
+		return "No release dates announced yet"
+	}
+	msg := "Digital release expected on " + formatDate(s.ExpectedDigital)
+	if s.Estimated {
+		msg += " (estimated)"
+	}
+	return msg
+}
+
+func formatDate(date string) string {
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return date
+	}
+	return t.Format("Jan 2, 2006")
+}
+
+type Certification struct {
+	Certification string `json:"certification"`
+	Meaning       string `json:"meaning"`
+	Order         int    `json:"order"`
+}
+
+type ContentRatingsResponse struct {
+	ID      int             `json:"id"`
+	Results []ContentRating `json:"results"`
+}
+
+type ContentRating struct {
+	ISO31661    string   `json:"iso_3166_1"`
+	Rating      string   `json:"rating"`
+	Descriptors []string `json:"descriptors"`
+}
+
+// Rating returns the show's rating in country, or "".
+func (r ContentRatingsResponse) Rating(country string) string {
+	for _, cr := range r.Results {
+		if cr.ISO31661 == country {
+			return cr.Rating
+		}
+	}
+	return ""
+}
+
+// Certification returns the movie's rating in country, preferring the
+// theatrical release's, or "".
+func (r ReleaseDatesResponse) Certification(country string) string {
+	var fallback string
+	for _, rd := range r.Region(country) {
+		if rd.Certification == "" {
+			continue
+		}
+		if rd.Type == ReleaseTheatrical {
+			return rd.Certification
+		}
+		if fallback == "" {
+			fallback = rd.Certification
+		}
+	}
+	return fallback
+}
+
+// Certification returns the movie's rating in country, or "".
+func (m MovieDetails) Certification(country string) string {
+	if m.ReleaseDates == nil {
+		return ""
+	}
+	return m.ReleaseDates.Certification(country)
+}
+
+// Certification returns the show's rating in country, or "".
+func (t TVDetails) Certification(country string) string {
+	if t.ContentRatings == nil {
+		return ""
+	}
+	return t.ContentRatings.Rating(country)
+}
+
+// FindResponse lists the TMDB objects matching an external ID. TMDB
+// returns at most a handful, usually one.
+type FindResponse struct {
+	MovieResults     []SearchResult `json:"movie_results"`
+	TVResults        []SearchResult `json:"tv_results"`
+	PersonResults    []FindPerson   `json:"person_results"`
+	TVEpisodeResults []FindEpisode  `json:"tv_episode_results"`
+	TVSeasonResults  []FindSeason   `json:"tv_season_results"`
+}
+
+type FindPerson struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	ProfilePath string `json:"profile_path"`
+}
+
+type FindEpisode struct {
+	Episode
+	ShowID int `json:"show_id"`
+}
+
+type FindSeason struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	SeasonNumber int    `json:"season_number"`
+	ShowID       int    `json:"show_id"`
+}
+
+// Path returns the Orbit page of the first match, preferring movies, then
+// shows, people, episodes and seasons, or "" when nothing matched.
+func (f FindResponse) Path() string {
+	switch {
+	case len(f.MovieResults) > 0:
+		return fmt.Sprintf("/movie/%d", f.MovieResults[0].ID)
+	case len(f.TVResults) > 0:
+		return fmt.Sprintf("/tv/%d", f.TVResults[0].ID)
+	case len(f.PersonResults) > 0:
+		return fmt.Sprintf("/person/%d", f.PersonResults[0].ID)
+	case len(f.TVEpisodeResults) > 0:
+		e := f.TVEpisodeResults[0]
+		return fmt.Sprintf("/tv/%d/season/%d", e.ShowID, e.SeasonNumber)
+	case len(f.TVSeasonResults) > 0:
+		s := f.TVSeasonResults[0]
+		return fmt.Sprintf("/tv/%d/season/%d", s.ShowID, s.SeasonNumber)
+	}
+	return ""
+}
+
+type AlternativeTitle struct {
+	ISO31661 string `json:"iso_3166_1"`
+	Title    string `json:"title"`
+	Type     string `json:"type"`
+}
+
+// AlternativeTitles holds a movie's titles, or a show's results; TMDB
+// names the list differently for each.
+type AlternativeTitles struct {
+	ID      int                `json:"id,omitempty"`
+	Titles  []AlternativeTitle `json:"titles,omitempty"`
+	Results []AlternativeTitle `json:"results,omitempty"`
+}
+
+func (a AlternativeTitles) All() []AlternativeTitle {
+	return append(append([]AlternativeTitle{}, a.Titles...), a.Results...)
+}
+
+type Translations struct {
+	ID           int           `json:"id,omitempty"`
+	Translations []Translation `json:"translations"`
+}
+
+type Translation struct {
+	ISO31661    string          `json:"iso_3166_1"`
+	ISO6391     string          `json:"iso_639_1"`
+	Name        string          `json:"name"`
+	EnglishName string          `json:"english_name"`
+	Data        TranslationData `json:"data"`
+}
+
+// TranslationData carries Title for movies and Name for shows.
+type TranslationData struct {
+	Title    string `json:"title,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Overview string `json:"overview"`
+	Tagline  string `json:"tagline,omitempty"`
+}
+
+func (d TranslationData) DisplayTitle() string {
+	if d.Title != "" {
+		return d.Title
+	}
+	return d.Name
+}
+
+// AltTitlesResponse is what the alternative-titles endpoints return.
+type AltTitlesResponse struct {
+	ID           int                `json:"id"`
+	Titles       []AlternativeTitle `json:"titles"`
+	Translations []Translation      `json:"translations"`
+	SceneTitles  []string           `json:"scene_titles"`
+}
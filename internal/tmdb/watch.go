@@ -0,0 +1,71 @@
+package tmdb
+
+import (
+	"fmt"
+	"sort"
+)
+
+func (c *Client) GetMovieWatchProviders(id int) (*WatchProvidersResponse, error) {
+	url := fmt.Sprintf("%s/movie/%d/watch/providers", baseURL, id)
+
+	var result WatchProvidersResponse
+	if err := c.doRequest(url, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *Client) GetTVWatchProviders(id int) (*WatchProvidersResponse, error) {
+	url := fmt.Sprintf("%s/tv/%d/watch/providers", baseURL, id)
+
+	var result WatchProvidersResponse
+	if err := c.doRequest(url, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetWatchProviderList returns every provider TMDB knows for a region,
+// movie and TV lists merged and ordered by the region's display priority.
+func (c *Client) GetWatchProviderList(region string) ([]Provider, error) {
+	var providers []Provider
+	seen := make(map[int]bool)
+
+	for _, kind := range []string{"movie", "tv"} {
+		url := fmt.Sprintf("%s/watch/providers/%s?watch_region=%s&language=en-US", baseURL, kind, region)
+
+		var result struct {
+			Results []Provider `json:"results"`
+		}
+		if err := c.doRequest(url, &result); err != nil {
+			return nil, err
+		}
+		for _, p := range result.Results {
+			if seen[p.ProviderID] {
+				continue
+			}
+			seen[p.ProviderID] = true
+			providers = append(providers, p)
+		}
+	}
+
+	sort.SliceStable(providers, func(i, j int) bool {
+		return providers[i].DisplayPriority < providers[j].DisplayPriority
+	})
+	return providers, nil
+}
+
+func (c *Client) GetWatchRegions() ([]WatchRegion, error) {
+	url := fmt.Sprintf("%s/watch/providers/regions?language=en-US", baseURL)
+
+	var result struct {
+		Results []WatchRegion `json:"results"`
+	}
+	if err := c.doRequest(url, &result); err != nil {
+		return nil, err
+	}
+	sort.Slice(result.Results, func(i, j int) bool {
+		return result.Results[i].EnglishName < result.Results[j].EnglishName
+	})
+	return result.Results, nil
+}
@@ -0,0 +1,114 @@
+package translit
+
+import "strings"
+
+// hiragana maps each kana to modified Hepburn, without macrons: long
+// vowels are written as spelled and ー is dropped. Katakana is folded onto
+// hiragana first.
+var hiragana = map[rune]string{
+	'あ': "a", 'い': "i", 'う': "u", 'え': "e", 'お': "o",
+	'か': "ka", 'き': "ki", 'く': "ku", 'け': "ke", 'こ': "ko",
+	'が': "ga", 'ぎ': "gi", 'ぐ': "gu", 'げ': "ge", 'ご': "go",
+	'さ': "sa", 'し': "shi", 'す': "su", 'せ': "se", 'そ': "so",
+	'ざ': "za", 'じ': "ji", 'ず': "zu", 'ぜ': "ze", 'ぞ': "zo",
+	'た': "ta", 'ち': "chi", 'つ': "tsu", 'て': "te", 'と': "to",
+	'だ': "da", 'ぢ': "ji", 'づ': "zu", 'で': "de", 'ど': "do",
+	'な': "na", 'に': "ni", 'ぬ': "nu", 'ね': "ne", 'の': "no",
+	'は': "ha", 'ひ': "hi", 'ふ': "fu", 'へ': "he", 'ほ': "ho",
+	'ば': "ba", 'び': "bi", 'ぶ': "bu", 'べ': "be", 'ぼ': "bo",
+	'ぱ': "pa", 'ぴ': "pi", 'ぷ': "pu", 'ぺ': "pe", 'ぽ': "po",
+	'ま': "ma", 'み': "mi", 'む': "mu", 'め': "me", 'も': "mo",
+	'や': "ya", 'ゆ': "yu", 'よ': "yo",
+	'ら': "ra", 'り': "ri", 'る': "ru", 'れ': "re", 'ろ': "ro",
+	'わ': "wa", 'ゐ': "i", 'ゑ': "e", 'を': "o", 'ん': "n",
+	'ゔ': "vu",
+	'ぁ': "a", 'ぃ': "i", 'ぅ': "u", 'ぇ': "e", 'ぉ': "o",
+	'ゃ': "ya", 'ゅ': "yu", 'ょ': "yo", 'ゎ': "wa",
+	'ゕ': "ka", 'ゖ': "ke",
+}
+
+// Katakana with no hiragana counterpart.
+var katakanaOnly = map[rune]string{
+	'ヷ': "va", 'ヸ': "vi", 'ヹ': "ve", 'ヺ': "vo",
+	'ー': "", '・': " ",
+}
+
+func isKana(r rune) bool {
+	return (r >= 0x3041 && r <= 0x3096) || (r >= 0x30a1 && r <= 0x30fc)
+}
+
+func hasKana(s string) bool {
+	return strings.IndexFunc(s, isKana) >= 0
+}
+
+// toHiragana folds katakana onto hiragana, which sits 0x60 below it.
+func toHiragana(r rune) rune {
+	if r >= 0x30a1 && r <= 0x30f6 {
+		return r - 0x60
+	}
+	return r
+}
+
+func isSmallY(r rune) bool { return r == 'ゃ' || r == 'ゅ' || r == 'ょ' }
+
+func isSmallVowel(r rune) bool {
+	return r == 'ぁ' || r == 'ぃ' || r == 'ぅ' || r == 'ぇ' || r == 'ぉ'
+}
+
+// kana romanizes the syllable starting at runes[0] and reports how many
+// runes it used: contracted sounds like きゃ and ファ span two, and a
+// sokuon っ doubles the consonant after it.
+func kana(runes []rune) (string, int) {
+	if out, ok := katakanaOnly[runes[0]]; ok {
+		return out, 1
+	}
+	r := toHiragana(runes[0])
+
+	if r == 'っ' {
+		if len(runes) < 2 || !isKana(runes[1]) {
+			return "", 1
+		}
+		next, n := kana(runes[1:])
+		switch {
+		case strings.HasPrefix(next, "ch"):
+			return "t" + next, n + 1
+		case next != "" && !strings.ContainsRune("aiueon", rune(next[0])):
+			return next[:1] + next, n + 1
+		default:
+			return next, n + 1
+		}
+	}
+
+	base, ok := hiragana[r]
+	if !ok {
+		return "", 1
+	}
+	if len(runes) < 2 {
+		return base, 1
+	}
+	small := toHiragana(runes[1])
+
+	// きゃ → kya, しゃ → sha, じゃ → ja.
+	if isSmallY(small) && strings.HasSuffix(base, "i") && len(base) > 1 {
+		stem := strings.TrimSuffix(base, "i")
+		vowel := hiragana[small][1:]
+		if stem == "sh" || stem == "ch" || stem == "j" {
+			return stem + vowel, 2
+		}
+		return stem + "y" + vowel, 2
+	}
+
+	// Loanword sounds: ファ → fa, ティ → ti, ウィ → wi, イェ → ye.
+	if isSmallVowel(small) {
+		vowel := hiragana[small]
+		stem := base[:len(base)-1]
+		switch r {
+		case 'う':
+			stem = "w"
+		case 'い':
+			stem = "y"
+		}
+		return stem + vowel, 2
+	}
+	return base, 1
+}
@@ -0,0 +1,329 @@
+package translit
+
+// pinyin gives the most common Mandarin reading, without tones, of the
+// characters that turn up most in film and show titles. Characters
+// missing here make Latin report ok=false rather than guess.
+var pinyin = map[rune]string{}
+
+func init() {
+	for syllable, chars := range pinyinTable {
+		for _, r := range chars {
+			pinyin[r] = syllable
+		}
+	}
+}
+
+var pinyinTable = map[string]string{
+	"a":      "阿啊",
+	"ai":     "爱哀艾碍",
+	"an":     "安暗岸按案",
+	"ang":    "昂",
+	"ao":     "奥傲澳",
+	"ba":     "八巴把爸霸拔吧",
+	"bai":    "白百败拜",
+	"ban":    "半班般板办",
+	"bang":   "帮邦棒",
+	"bao":    "宝报保抱包暴",
+	"bei":    "北被背杯悲贝",
+	"ben":    "本奔",
+	"bi":     "比必笔毕闭碧壁",
+	"bian":   "边变便编",
+	"biao":   "表标",
+	"bie":    "别",
+	"bin":    "宾冰",
+	"bing":   "兵病并",
+	"bo":     "波博伯薄",
+	"bu":     "不步部布",
+	"cai":    "才菜彩财",
+	"can":    "参残",
+	"cang":   "藏苍",
+	"cao":    "草曹",
+	"ce":     "策",
+	"ceng":   "曾",
+	"cha":    "查茶",
+	"chan":   "产",
+	"chang":  "长常场唱",
+	"chao":   "超朝潮",
+	"che":    "车",
+	"chen":   "陈沉晨",
+	"cheng":  "成城程乘",
+	"chi":    "吃持赤池",
+	"chong":  "冲虫",
+	"chou":   "仇",
+	"chu":    "出初处除",
+	"chuan":  "传川船穿",
+	"chuang": "创床",
+	"chun":   "春纯",
+	"ci":     "次此词",
+	"cong":   "从聪",
+	"cun":    "村存",
+	"cuo":    "错",
+	"da":     "大打达",
+	"dai":    "代带待",
+	"dan":    "单但蛋丹胆",
+	"dang":   "当党",
+	"dao":    "到道刀岛倒盗",
+	"de":     "的得德",
+	"deng":   "等灯登",
+	"di":     "地第弟帝敌底",
+	"dian":   "电点店",
+	"diao":   "调",
+	"die":    "爹",
+	"ding":   "定顶",
+	"dong":   "东动冬洞懂",
+	"dou":    "都斗",
+	"du":     "读独度毒",
+	"duan":   "断段短",
+	"dui":    "对队",
+	"dun":    "顿",
+	"duo":    "多夺",
+	"e":      "恶饿",
+	"en":     "恩",
+	"er":     "二儿而耳",
+	"fa":     "发法",
+	"fan":    "反饭犯凡",
+	"fang":   "方放房",
+	"fei":    "飞非",
+	"fen":    "分份",
+	"feng":   "风封疯峰锋凤",
+	"fo":     "佛",
+	"fu":     "父福夫服府富复",
+	"gai":    "该改",
+	"gan":    "感干敢",
+	"gang":   "刚港钢",
+	"gao":    "高告",
+	"ge":     "个歌哥格各",
+	"gei":    "给",
+	"gen":    "跟根",
+	"geng":   "更",
+	"gong":   "工公功宫共攻",
+	"gou":    "狗够",
+	"gu":     "古故姑骨谷",
+	"gua":    "瓜",
+	"guai":   "怪",
+	"guan":   "关观官管",
+	"guang":  "光广",
+	"gui":    "鬼贵归",
+	"guo":    "国过果",
+	"hai":    "还海孩害",
+	"han":    "汉寒韩",
+	"hao":    "好号豪",
+	"he":     "和河何合",
+	"hei":    "黑",
+	"hen":    "很恨",
+	"hong":   "红洪",
+	"hou":    "后候",
+	"hu":     "湖虎护胡",
+	"hua":    "花话华画",
+	"huai":   "坏怀",
+	"huan":   "欢环换",
+	"huang":  "黄皇",
+	"hui":    "回会灰",
+	"hun":    "婚魂",
+	"huo":    "火活或",
+	"ji":     "机几级记急极鸡集纪继计寂",
+	"jia":    "家加假佳",
+	"jian":   "见间剑建件",
+	"jiang":  "江将讲",
+	"jiao":   "叫教交脚",
+	"jie":    "姐街节界接结解",
+	"jin":    "金进今近",
+	"jing":   "经京警惊静井镜",
+	"jiu":    "九就酒旧救",
+	"ju":     "局据剧",
+	"jue":    "觉绝决",
+	"jun":    "军君",
+	"kai":    "开",
+	"kan":    "看",
+	"kang":   "康",
+	"kao":    "考",
+	"ke":     "可客科",
+	"kong":   "空恐",
+	"kou":    "口",
+	"ku":     "苦哭",
+	"kuai":   "快",
+	"kuang":  "狂",
+	"la":     "拉",
+	"lai":    "来",
+	"lan":    "蓝兰",
+	"lang":   "狼浪郎",
+	"lao":    "老",
+	"le":     "了乐",
+	"lei":    "雷泪",
+	"leng":   "冷",
+	"li":     "里力理李立丽离礼",
+	"lian":   "连脸恋练",
+	"liang":  "两亮良凉",
+	"lie":    "烈猎",
+	"lin":    "林临",
+	"ling":   "零灵龄",
+	"liu":    "六流留刘",
+	"long":   "龙",
+	"lou":    "楼",
+	"lu":     "路陆鹿",
+	"lv":     "绿旅律",
+	"luan":   "乱",
+	"lun":    "论轮",
+	"luo":    "落罗",
+	"ma":     "马妈吗",
+	"mai":    "买卖",
+	"man":    "满慢",
+	"mang":   "忙",
+	"mao":    "毛猫冒",
+	"mei":    "美没每妹梅",
+	"men":    "门们",
+	"meng":   "梦猛",
+	"mi":     "米密迷秘",
+	"mian":   "面",
+	"miao":   "妙",
+	"min":    "民",
+	"ming":   "明名命",
+	"mo":     "魔末莫默",
+	"mu":     "母木目",
+	"na":     "那拿",
+	"nan":    "男南难",
+	"nao":    "脑",
+	"ne":     "呢",
+	"nei":    "内",
+	"neng":   "能",
+	"ni":     "你泥",
+	"nian":   "年念",
+	"niang":  "娘",
+	"niao":   "鸟",
+	"nin":    "您",
+	"ning":   "宁",
+	"niu":    "牛",
+	"nong":   "农",
+	"nu":     "怒",
+	"nv":     "女",
+	"pa":     "怕",
+	"pai":    "派",
+	"pang":   "旁胖",
+	"pao":    "跑",
+	"pei":    "配",
+	"peng":   "朋",
+	"pi":     "皮",
+	"pian":   "片骗",
+	"piao":   "漂",
+	"pin":    "品",
+	"ping":   "平",
+	"po":     "破婆",
+	"qi":     "七起气期奇其骑",
+	"qian":   "前千钱",
+	"qiang":  "强枪",
+	"qiao":   "桥",
+	"qie":    "切",
+	"qin":    "亲秦琴",
+	"qing":   "青情清请轻",
+	"qiu":    "秋球求",
+	"qu":     "去取区曲",
+	"quan":   "全权",
+	"que":    "却",
+	"qun":    "群",
+	"ran":    "然",
+	"re":     "热",
+	"ren":    "人认忍仁",
+	"ri":     "日",
+	"rong":   "容荣",
+	"ru":     "如入",
+	"ruo":    "若",
+	"san":    "三",
+	"sao":    "扫",
+	"se":     "色",
+	"sen":    "森",
+	"sha":    "杀沙",
+	"shan":   "山善闪",
+	"shang":  "上伤商",
+	"shao":   "少烧",
+	"she":    "社蛇射",
+	"shei":   "谁",
+	"shen":   "身神深什",
+	"sheng":  "生声圣胜",
+	"shi":    "是时十事世师使石食试市诗失",
+	"shou":   "手受首守",
+	"shu":    "书树数术",
+	"shuang": "双",
+	"shui":   "水睡",
+	"shuo":   "说",
+	"si":     "死四思丝私",
+	"song":   "送宋",
+	"su":     "速苏",
+	"sui":    "岁",
+	"sun":    "孙",
+	"suo":    "所锁",
+	"ta":     "他她它塔",
+	"tai":    "太台",
+	"tan":    "谈探",
+	"tang":   "堂唐糖",
+	"tao":    "逃",
+	"te":     "特",
+	"ti":     "提体题",
+	"tian":   "天田",
+	"tiao":   "条跳",
+	"tie":    "铁",
+	"ting":   "听停",
+	"tong":   "同通童",
+	"tou":    "头偷",
+	"tu":     "图土",
+	"tuan":   "团",
+	"tui":    "推",
+	"wa":     "娃",
+	"wai":    "外",
+	"wan":    "万完晚玩",
+	"wang":   "王网望忘",
+	"wei":    "为位未卫味",
+	"wen":    "问文温",
+	"wo":     "我卧",
+	"wu":     "五无舞武物雾",
+	"xi":     "西喜系戏洗习",
+	"xia":    "下夏侠",
+	"xian":   "先现仙险线",
+	"xiang":  "想向香相乡",
+	"xiao":   "小笑校",
+	"xie":    "写谢",
+	"xin":    "心新信",
+	"xing":   "星行性兴",
+	"xiong":  "熊兄雄",
+	"xiu":    "修",
+	"xu":     "需许续",
+	"xuan":   "选",
+	"xue":    "学雪血",
+	"xun":    "寻",
+	"ya":     "牙",
+	"yan":    "眼言烟严颜",
+	"yang":   "阳羊样",
+	"yao":    "要妖药",
+	"ye":     "夜也爷业叶野",
+	"yi":     "一以已意衣医亿",
+	"yin":    "因音银",
+	"ying":   "英影应赢",
+	"yong":   "永用勇",
+	"you":    "有又友由游",
+	"yu":     "雨鱼语玉遇与",
+	"yuan":   "元远原园缘",
+	"yue":    "月越",
+	"yun":    "云运",
+	"zai":    "在再",
+	"zan":    "咱",
+	"zao":    "早",
+	"ze":     "则",
+	"zen":    "怎",
+	"zhan":   "战站",
+	"zhang":  "张",
+	"zhao":   "找照",
+	"zhe":    "这者",
+	"zhen":   "真",
+	"zheng":  "正争",
+	"zhi":    "之只知直指纸",
+	"zhong":  "中种重终",
+	"zhou":   "周",
+	"zhu":    "主住猪",
+	"zhuan":  "转",
+	"zhuang": "装",
+	"zi":     "子字自",
+	"zong":   "总",
+	"zou":    "走",
+	"zu":     "族足",
+	"zui":    "最罪",
+	"zuo":    "做作坐",
+}
@@ -0,0 +1,68 @@
+// Package translit romanizes Cyrillic, Greek, Japanese kana and common
+// Chinese characters so non-Latin titles can be turned into the Latin
+// search terms most trackers index.
+package translit
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Latin returns s lowercased with Cyrillic, Greek, kana and Han
+// characters romanized. Other characters pass through unchanged. ok is
+// false when some non-Latin letter had no romanization, such as a rare
+// Han character, or kanji in a Japanese title, which cannot be read
+// without a dictionary.
+func Latin(s string) (latin string, ok bool) {
+	s = strings.ToLower(s)
+	japanese := hasKana(s)
+	runes := []rune(s)
+
+	var b strings.Builder
+	ok = true
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case isKana(r):
+			out, n := kana(runes[i:])
+			b.WriteString(out)
+			i += n - 1
+		case unicode.Is(unicode.Han, r):
+			if p, found := pinyin[r]; found && !japanese {
+				// Syllables are separate words, as in most release names.
+				b.WriteString(" " + p + " ")
+			} else {
+				ok = false
+			}
+		case unicode.Is(unicode.Cyrillic, r):
+			if l, found := cyrillic[r]; found {
+				b.WriteString(l)
+			} else {
+				ok = false
+			}
+		case unicode.Is(unicode.Greek, r):
+			if l, found := greek[r]; found {
+				b.WriteString(l)
+			} else {
+				ok = false
+			}
+		default:
+			if unicode.IsLetter(r) && !unicode.Is(unicode.Latin, r) {
+				ok = false
+			}
+			b.WriteRune(r)
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " "), ok
+}
+
+// NonLatin reports whether s contains letters outside the Latin script,
+// i.e. whether its native form and its romanization differ.
+func NonLatin(s string) bool {
+	for _, r := range s {
+		if unicode.IsLetter(r) && !unicode.Is(unicode.Latin, r) {
+			return true
+		}
+	}
+	return false
+}
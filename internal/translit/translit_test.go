@@ -0,0 +1,50 @@
+package translit
+
+import "testing"
+
+func TestLatin(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   string
+		wantOK bool
+	}{
+		{"Москва слезам не верит", "moskva slezam ne verit", true},
+		{"Ёлки", "elki", true},
+		{"Щелкунчик", "shchelkunchik", true},
+		{"Ο Θίασος", "o thiasos", true},
+		{"となりのトトロ", "tonarinototoro", true},
+		{"ちゃっかり", "chakkari", true},
+		{"カウボーイビバップ", "kauboibibappu", true},
+		{"ファイト・クラブ", "faito kurabu", true},
+		{"卧虎藏龙", "wo hu cang long", true},
+		{"英雄", "ying xiong", true},
+		{"Amélie", "amélie", true},
+		// Kanji in a Japanese title need a dictionary to read.
+		{"千と千尋の神隠し", "tonoshi", false},
+		{"سلام", "سلام", false},
+	}
+	for _, tt := range tests {
+		got, ok := Latin(tt.in)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("Latin(%q) = %q, %v; want %q, %v", tt.in, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestNonLatin(t *testing.T) {
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{"Amélie", false},
+		{"Fight Club 2", false},
+		{"Ёлки", true},
+		{"となりのトトロ", true},
+		{"Léon: The Professional / Леон", true},
+	}
+	for _, tt := range tests {
+		if got := NonLatin(tt.in); got != tt.want {
+			t.Errorf("NonLatin(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package ttlcache
+
+import (
+	"sync"
+	"time"
+)
+
+type entry[V any] struct {
+	value   V
+	fetched time.Time
+}
+
+// Cache remembers up to max values for ttl.
+type Cache[V any] struct {
+	ttl time.Duration
+	max int
+
+	mu      sync.Mutex
+	entries map[string]entry[V]
+}
+
+func New[V any](ttl time.Duration, max int) *Cache[V] {
+	return &Cache[V]{ttl: ttl, max: max, entries: make(map[string]entry[V])}
+}
+
+// Get returns the value stored under key unless it has expired.
+func (c *Cache[V]) Get(key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok || time.Since(e.fetched) >= c.ttl {
+		var zero V
+		return zero, false
+	}
+	return e.value, true
+}
+
+// Put stores a value. A full cache first drops its expired entries and,
+// if still full, the oldest one.
+func (c *Cache[V]) Put(key string, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.max {
+		oldest := ""
+		for k, e := range c.entries {
+			if time.Since(e.fetched) >= c.ttl {
+				delete(c.entries, k)
+			} else if oldest == "" || e.fetched.Before(c.entries[oldest].fetched) {
+				oldest = k
+			}
+		}
+		if len(c.entries) >= c.max {
+			delete(c.entries, oldest)
+		}
+	}
+	c.entries[key] = entry[V]{value: value, fetched: time.Now()}
+}
@@ -0,0 +1,75 @@
+package ttlcache
+
+import (
+	"testing"
+	"time"
+)
+
+// aged stores value in c as if it had been fetched age ago.
+func aged(c *Cache[int], key string, value int, age time.Duration) {
+	c.Put(key, value)
+	c.mu.Lock()
+	c.entries[key] = entry[int]{value: value, fetched: time.Now().Add(-age)}
+	c.mu.Unlock()
+}
+
+func TestCache(t *testing.T) {
+	tests := []struct {
+		name string
+		fill func(c *Cache[int])
+		want map[string]bool
+	}{
+		{
+			name: "full cache drops the oldest",
+			fill: func(c *Cache[int]) {
+				aged(c, "a", 1, 3*time.Minute)
+				aged(c, "b", 2, 2*time.Minute)
+				aged(c, "c", 3, time.Minute)
+				c.Put("d", 4)
+			},
+			want: map[string]bool{"a": false, "b": true, "c": true, "d": true},
+		},
+		{
+			name: "expired entries go before the oldest live one",
+			fill: func(c *Cache[int]) {
+				aged(c, "a", 1, 3*time.Minute)
+				aged(c, "b", 2, 2*time.Hour)
+				aged(c, "c", 3, time.Minute)
+				c.Put("d", 4)
+			},
+			want: map[string]bool{"a": true, "b": false, "c": true, "d": true},
+		},
+		{
+			name: "replacing a key evicts nothing",
+			fill: func(c *Cache[int]) {
+				aged(c, "a", 1, 3*time.Minute)
+				aged(c, "b", 2, 2*time.Minute)
+				aged(c, "c", 3, time.Minute)
+				c.Put("a", 5)
+			},
+			want: map[string]bool{"a": true, "b": true, "c": true},
+		},
+		{
+			name: "expired entries are misses",
+			fill: func(c *Cache[int]) {
+				aged(c, "a", 1, 2*time.Hour)
+				c.Put("b", 2)
+			},
+			want: map[string]bool{"a": false, "b": true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New[int](time.Hour, 3)
+			tt.fill(c)
+			if len(c.entries) > c.max {
+				t.Errorf("cache holds %d entries, max %d", len(c.entries), c.max)
+			}
+			for key, want := range tt.want {
+				if _, ok := c.Get(key); ok != want {
+					t.Errorf("Get(%q) ok = %v, want %v", key, ok, want)
+				}
+			}
+		})
+	}
+}
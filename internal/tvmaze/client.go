@@ -0,0 +1,397 @@
+// Package tvmaze is a keyless metadata provider for TV shows, used when
+// TMDB is unavailable and to fill episode data TMDB is missing.
+package tvmaze
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/unedtamps/orbit/internal/metadata"
+	"github.com/unedtamps/orbit/internal/tmdb"
+	"github.com/unedtamps/orbit/internal/ttlcache"
+)
+
+const (
+	baseURL = "https://api.tvmaze.com"
+	showTTL = 6 * time.Hour
+	// mazeIDTTL is how long a TMDB to TVmaze ID mapping is kept; it only
+	// changes when either side merges shows.
+	mazeIDTTL = 7 * 24 * time.Hour
+	// cacheSize caps how many shows and ID mappings are kept.
+	cacheSize = 1024
+)
+
+var (
+	_ metadata.MetadataProvider = (*Client)(nil)
+	_ metadata.Filler           = (*Client)(nil)
+)
+
+// IDSource maps a TMDB show ID to its IMDb and TVDB IDs, which is how
+// TVmaze shows are looked up. tmdb.Client and metadata.Snapshot both are.
+type IDSource interface {
+	GetTVExternalIDs(id int) (*tmdb.ExternalIDs, error)
+}
+
+type showEntry struct {
+	show     *Show
+	episodes []Episode
+}
+
+type Client struct {
+	http *http.Client
+	ids  []IDSource
+
+	mazeIDs *ttlcache.Cache[int]
+	shows   *ttlcache.Cache[*showEntry]
+}
+
+// New returns a client that maps TMDB IDs through ids, asked in order.
+func New(ids ...IDSource) *Client {
+	return &Client{
+		http:    &http.Client{Timeout: 10 * time.Second},
+		ids:     ids,
+		mazeIDs: ttlcache.New[int](mazeIDTTL, cacheSize),
+		shows:   ttlcache.New[*showEntry](showTTL, cacheSize),
+	}
+}
+
+func (c *Client) Name() string { return "tvmaze" }
+
+func (c *Client) doRequest(url string, result interface{}) error {
+	resp, err := c.http.Get(url)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("TVmaze API error (status %d)", resp.StatusCode)
+	}
+	if err := json.Unmarshal(body, result); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+// lookup finds a TVmaze show by TVDB ID, then by IMDb ID.
+func (c *Client) lookup(ext *tmdb.ExternalIDs) (int, error) {
+	var queries []string
+	if ext.TVDBID != 0 {
+		queries = append(queries, fmt.Sprintf("thetvdb=%d", ext.TVDBID))
+	}
+	if ext.IMDbID != "" {
+		queries = append(queries, "imdb="+neturl.QueryEscape(ext.IMDbID))
+	}
+
+	for _, q := range queries {
+		var show Show
+		if err := c.doRequest(baseURL+"/lookup/shows?"+q, &show); err == nil {
+			return show.ID, nil
+		}
+	}
+	return 0, errors.New("show not found on TVmaze")
+}
+
+// mazeID maps a TMDB show ID to a TVmaze one. known, when set, saves
+// asking the ID sources.
+func (c *Client) mazeID(tmdbID int, known *tmdb.ExternalIDs) (int, error) {
+	if id, ok := c.mazeIDs.Get(strconv.Itoa(tmdbID)); ok {
+		return id, nil
+	}
+
+	ext := known
+	if ext == nil {
+		for _, src := range c.ids {
+			if e, err := src.GetTVExternalIDs(tmdbID); err == nil {
+				ext = e
+				break
+			}
+		}
+	}
+	if ext == nil {
+		return 0, fmt.Errorf("no external IDs for TMDB show %d", tmdbID)
+	}
+
+	id, err := c.lookup(ext)
+	if err != nil {
+		return 0, err
+	}
+	c.mazeIDs.Put(strconv.Itoa(tmdbID), id)
+	return id, nil
+}
+
+// show returns a show with its episodes, cached for showTTL.
+func (c *Client) show(mazeID int) (*showEntry, error) {
+	key := strconv.Itoa(mazeID)
+	if entry, ok := c.shows.Get(key); ok {
+		return entry, nil
+	}
+
+	var show Show
+	if err := c.doRequest(fmt.Sprintf("%s/shows/%d?embed=nextepisode", baseURL, mazeID), &show); err != nil {
+		return nil, err
+	}
+	var episodes []Episode
+	if err := c.doRequest(fmt.Sprintf("%s/shows/%d/episodes?specials=1", baseURL, mazeID), &episodes); err != nil {
+		return nil, err
+	}
+
+	entry := &showEntry{show: &show, episodes: episodes}
+	c.shows.Put(key, entry)
+	return entry, nil
+}
+
+func (c *Client) MultiSearch(query string, page int) (*tmdb.MultiSearchResponse, error) {
+	return nil, metadata.ErrUnsupported
+}
+
+func (c *Client) GetMovieDetails(id int) (*tmdb.MovieDetails, error) {
+	return nil, metadata.ErrUnsupported
+}
+
+// GetTVDetails builds a TMDB-shaped show. Images and genre IDs have no
+// TVmaze equivalent and are left empty.
+func (c *Client) GetTVDetails(id int) (*tmdb.TVDetails, error) {
+	mazeID, err := c.mazeID(id, nil)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := c.show(mazeID)
+	if err != nil {
+		return nil, err
+	}
+	show := entry.show
+
+	tv := &tmdb.TVDetails{
+		ID:           id,
+		Name:         show.Name,
+		OriginalName: show.Name,
+		Overview:     plainText(show.Summary),
+		FirstAirDate: show.Premiered,
+		LastAirDate:  show.Ended,
+		Homepage:     show.OfficialSite,
+		Status:       tmdbStatus(show.Status),
+		InProduction: show.Status == "Running",
+		VoteAverage:  show.Rating.Average,
+		ExternalIDs:  &tmdb.ExternalIDs{IMDbID: show.Externals.IMDb, TVDBID: show.Externals.TheTVDB},
+	}
+	for _, g := range show.Genres {
+		tv.Genres = append(tv.Genres, tmdb.Genre{Name: g})
+	}
+	for _, n := range []*Network{show.Network, show.WebChannel} {
+		if n == nil {
+			continue
+		}
+		network := tmdb.Network{Name: n.Name}
+		if n.Country != nil {
+			network.OriginCountry = n.Country.Code
+		}
+		tv.Networks = append(tv.Networks, network)
+	}
+
+	today := time.Now().Format("2006-01-02")
+	seasons := make(map[int]*tmdb.Season)
+	for _, e := range tmdbEpisodes(entry.episodes) {
+		s, ok := seasons[e.SeasonNumber]
+		if !ok {
+			s = &tmdb.Season{SeasonNumber: e.SeasonNumber, Name: seasonName(e.SeasonNumber), AirDate: e.AirDate}
+			seasons[e.SeasonNumber] = s
+		}
+		s.EpisodeCount++
+		if e.SeasonNumber > 0 {
+			tv.NumberOfEpisodes++
+		}
+		if e.AirDate != "" && e.AirDate <= today {
+			ep := e
+			tv.LastEpisodeToAir = &ep
+			if show.Ended == "" {
+				tv.LastAirDate = e.AirDate
+			}
+		}
+	}
+	for _, s := range seasons {
+		tv.Seasons = append(tv.Seasons, *s)
+		if s.SeasonNumber > 0 {
+			tv.NumberOfSeasons++
+		}
+	}
+	sort.Slice(tv.Seasons, func(i, j int) bool {
+		return tv.Seasons[i].SeasonNumber < tv.Seasons[j].SeasonNumber
+	})
+	if next := show.Embedded.NextEpisode; next != nil {
+		tv.NextEpisodeToAir = nextEpisode(next, entry.episodes)
+	}
+	return tv, nil
+}
+
+func (c *Client) GetSeasonDetails(tvID int, seasonNumber int) (*tmdb.SeasonDetails, error) {
+	mazeID, err := c.mazeID(tvID, nil)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := c.show(mazeID)
+	if err != nil {
+		return nil, err
+	}
+
+	season := &tmdb.SeasonDetails{SeasonNumber: seasonNumber, Name: seasonName(seasonNumber)}
+	for _, e := range tmdbEpisodes(entry.episodes) {
+		if e.SeasonNumber != seasonNumber {
+			continue
+		}
+		if season.AirDate == "" {
+			season.AirDate = e.AirDate
+		}
+		season.Episodes = append(season.Episodes, e)
+	}
+	if len(season.Episodes) == 0 {
+		return nil, fmt.Errorf("season %d not found on TVmaze", seasonNumber)
+	}
+	return season, nil
+}
+
+// FillTVDetails fills the overview and next episode when TMDB lacks them.
+func (c *Client) FillTVDetails(tv *tmdb.TVDetails) ([]string, error) {
+	running := tv.InProduction || tv.Status == "Returning Series"
+	if tv.Overview != "" && (tv.NextEpisodeToAir != nil || !running) {
+		return nil, nil
+	}
+
+	mazeID, err := c.mazeID(tv.ID, tv.ExternalIDs)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := c.show(mazeID)
+	if err != nil {
+		return nil, err
+	}
+
+	var filled []string
+	if tv.Overview == "" {
+		if s := plainText(entry.show.Summary); s != "" {
+			tv.Overview = s
+			filled = append(filled, "overview")
+		}
+	}
+	if tv.NextEpisodeToAir == nil && running && entry.show.Embedded.NextEpisode != nil {
+		tv.NextEpisodeToAir = nextEpisode(entry.show.Embedded.NextEpisode, entry.episodes)
+		filled = append(filled, "next_episode_to_air")
+	}
+	return filled, nil
+}
+
+// FillSeasonDetails fills episode names, overviews and air dates that
+// TMDB has left blank, matching episodes by number.
+func (c *Client) FillSeasonDetails(tvID int, season *tmdb.SeasonDetails) ([]string, error) {
+	gaps := false
+	for _, e := range season.Episodes {
+		if e.Overview == "" || e.AirDate == "" || placeholderName(e) {
+			gaps = true
+			break
+		}
+	}
+	if !gaps {
+		return nil, nil
+	}
+
+	mazeID, err := c.mazeID(tvID, nil)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := c.show(mazeID)
+	if err != nil {
+		return nil, err
+	}
+
+	byNumber := make(map[int]tmdb.Episode)
+	for _, e := range tmdbEpisodes(entry.episodes) {
+		if e.SeasonNumber == season.SeasonNumber {
+			byNumber[e.EpisodeNumber] = e
+		}
+	}
+
+	var filled []string
+	for i := range season.Episodes {
+		e := &season.Episodes[i]
+		m, ok := byNumber[e.EpisodeNumber]
+		if !ok {
+			continue
+		}
+		path := fmt.Sprintf("episodes[%d].", i)
+		if e.Overview == "" && m.Overview != "" {
+			e.Overview = m.Overview
+			filled = append(filled, path+"overview")
+		}
+		if e.AirDate == "" && m.AirDate != "" {
+			e.AirDate = m.AirDate
+			filled = append(filled, path+"air_date")
+		}
+		if placeholderName(*e) && !placeholderName(m) {
+			e.Name = m.Name
+			filled = append(filled, path+"name")
+		}
+	}
+	return filled, nil
+}
+
+// tmdbEpisodes converts TVmaze episodes, numbering specials as season 0
+// in air order the way TMDB does.
+func tmdbEpisodes(episodes []Episode) []tmdb.Episode {
+	out := make([]tmdb.Episode, 0, len(episodes))
+	specials := 0
+	for _, e := range episodes {
+		te := tmdb.Episode{
+			Name:        e.Name,
+			Overview:    plainText(e.Summary),
+			AirDate:     e.AirDate,
+			Runtime:     e.Runtime,
+			VoteAverage: e.Rating.Average,
+		}
+		if e.Number == nil {
+			specials++
+			te.EpisodeNumber = specials
+		} else {
+			te.SeasonNumber = e.Season
+			te.EpisodeNumber = *e.Number
+		}
+		out = append(out, te)
+	}
+	return out
+}
+
+// nextEpisode converts next using the numbering tmdbEpisodes gives it.
+func nextEpisode(next *Episode, episodes []Episode) *tmdb.Episode {
+	all := tmdbEpisodes(episodes)
+	for i, e := range episodes {
+		if e.ID == next.ID {
+			return &all[i]
+		}
+	}
+	ep := tmdbEpisodes([]Episode{*next})[0]
+	return &ep
+}
+
+// placeholderName reports names like "Episode 3" that TMDB uses before an
+// episode's title is known.
+func placeholderName(e tmdb.Episode) bool {
+	return e.Name == "" || strings.EqualFold(e.Name, fmt.Sprintf("Episode %d", e.EpisodeNumber))
+}
+
+func seasonName(n int) string {
+	if n == 0 {
+		return "Specials"
+	}
+	return fmt.Sprintf("Season %d", n)
+}
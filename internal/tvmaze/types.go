@@ -0,0 +1,76 @@
+package tvmaze
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+type Show struct {
+	ID           int       `json:"id"`
+	Name         string    `json:"name"`
+	Language     string    `json:"language"`
+	Genres       []string  `json:"genres"`
+	Status       string    `json:"status"`
+	Premiered    string    `json:"premiered"`
+	Ended        string    `json:"ended"`
+	OfficialSite string    `json:"officialSite"`
+	Summary      string    `json:"summary"`
+	Rating       Rating    `json:"rating"`
+	Network      *Network  `json:"network"`
+	WebChannel   *Network  `json:"webChannel"`
+	Externals    Externals `json:"externals"`
+	Embedded     struct {
+		NextEpisode *Episode `json:"nextepisode"`
+	} `json:"_embedded"`
+}
+
+type Rating struct {
+	Average float64 `json:"average"`
+}
+
+type Network struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	Country *struct {
+		Code string `json:"code"`
+	} `json:"country"`
+}
+
+type Externals struct {
+	TheTVDB int    `json:"thetvdb"`
+	IMDb    string `json:"imdb"`
+}
+
+// Episode numbers are nil for specials, which TVmaze keeps in the season
+// they aired in rather than a season 0.
+type Episode struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	Season  int    `json:"season"`
+	Number  *int   `json:"number"`
+	Type    string `json:"type"`
+	AirDate string `json:"airdate"`
+	Runtime int    `json:"runtime"`
+	Summary string `json:"summary"`
+	Rating  Rating `json:"rating"`
+}
+
+var tags = regexp.MustCompile(`<[^>]*>`)
+
+// plainText strips the HTML TVmaze uses in summaries.
+func plainText(s string) string {
+	return strings.TrimSpace(html.UnescapeString(tags.ReplaceAllString(s, "")))
+}
+
+// tmdbStatus maps TVmaze show statuses onto TMDB's wording.
+func tmdbStatus(s string) string {
+	switch s {
+	case "Running":
+		return "Returning Series"
+	case "To Be Determined":
+		return "Planned"
+	default:
+		return s
+	}
+}
@@ -1,35 +1,27 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
 
-	_ "github.com/unedtamps/orbit/docs"
 	"github.com/unedtamps/orbit/internal/config"
 	"github.com/unedtamps/orbit/internal/fetcher"
 	"github.com/unedtamps/orbit/internal/handler"
+	"github.com/unedtamps/orbit/internal/imagecache"
+	"github.com/unedtamps/orbit/internal/imdb"
+	"github.com/unedtamps/orbit/internal/metadata"
 	"github.com/unedtamps/orbit/internal/tmdb"
+	"github.com/unedtamps/orbit/internal/tvmaze"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/cors"
-	httpSwagger "github.com/swaggo/http-swagger/v2"
 
 	"github.com/webtor-io/go-jackett"
 )
 
-//	@title			OrbitSearch API
-//	@version		1.0
-//	@description	Content discovery API - Search for movies and TV series
-//	@termsOfService	http://swagger.io/terms/
-
-//	@contact.name	API Support
-//	@contact.url	http://www.swagger.io/support
-
-//	@license.name	Apache 2.0
-//	@license.url	http://www.apache.org/licenses/LICENSE-2.0.html
-
-// @BasePath	/
 func main() {
 	cfg, err := config.Load()
 	if err != nil {
@@ -46,17 +38,28 @@ func main() {
 
 	f := fetcher.New(j, cfg.APIURL, cfg.APIKey)
 	tmdbClient := tmdb.NewClient(cfg.TMDBAPIKey)
-	tmpl := handler.LoadTemplates(cfg.TemplateGlob)
-	h := handler.New(f, tmdbClient, tmpl)
-	tmdbH := handler.NewTMDBHandler(tmdbClient)
-	magnetH := handler.NewMagnetHandler(f, tmpl)
+	meta := newMetadataChain(cfg, tmdbClient)
+	imdbStore := openIMDbDataset(cfg.IMDbDataset)
+	images := imagecache.URLs{Proxy: cfg.ImageProxy, Resize: cfg.ImageResize}
+	tmpl := handler.LoadTemplates(cfg.TemplateGlob, images)
+	prefs := handler.Preferences{
+		WatchRegion:          cfg.WatchRegion,
+		CertificationCountry: cfg.CertificationCountry,
+		MaxMovieRating:       cfg.MaxMovieRating,
+		MaxTVRating:          cfg.MaxTVRating,
+	}
+	h := handler.New(f, tmdbClient, meta, imdbStore, tmpl, prefs)
+	tmdbH := handler.NewTMDBHandler(tmdbClient, meta, imdbStore, prefs)
+	magnetH := handler.NewMagnetHandler(f, tmdbClient, tmpl, prefs)
+	icalH := handler.NewICalHandler(tmdbClient, prefs, cfg.HostURL)
+	lookupH := handler.NewLookupHandler(tmdbClient, imdbStore)
 
 	r := chi.NewRouter()
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"https://*", "http://*"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
 		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		ExposedHeaders:   []string{"Link", "Deprecation"},
 		AllowCredentials: false,
 		MaxAge:           cfg.CORSMaxAge,
 	}))
@@ -64,33 +67,27 @@ func main() {
 	fileServer := http.FileServer(http.Dir(cfg.StaticDir))
 	r.Handle("/static/*", http.StripPrefix("/static/", fileServer))
 
-	r.Get("/", h.Index)
-	r.Get("/search", h.SearchPage)
-	r.Get("/movie/{id}", h.MovieDetailPage)
-	r.Get("/tv/{id}", h.TVDetailPage)
-	r.Get("/tv/{id}/season/{season}", h.SeasonPage)
-
-	r.Get("/api/search", tmdbH.Search)
-	r.Get("/api/movie/{id}", tmdbH.GetMovie)
-	r.Get("/api/tv/{id}", tmdbH.GetTV)
-	r.Get("/api/tv/{id}/season/{season}", tmdbH.GetSeason)
-	r.Get("/api/movie/{id}/reviews", tmdbH.GetMovieReviews)
-	r.Get("/api/tv/{id}/reviews", tmdbH.GetTVReviews)
-
-	r.Get("/api/trending/movies", tmdbH.GetTrendingMovies)
-	r.Get("/api/trending/tv", tmdbH.GetTrendingTV)
-
-	r.Get("/magnet/movie/{id}", magnetH.GetMovieMagnets)
-	r.Get("/magnet/episode/{id}/s{season}/e{episode}", magnetH.GetEpisodeMagnets)
+	if cfg.ImageProxy {
+		cache, err := imagecache.New(cfg.ImageCacheDir, int64(cfg.ImageCacheMaxMB)<<20, cfg.ImageResize)
+		if err != nil {
+			log.Fatalf("Failed to create image cache: %v", err)
+		}
+		r.Get("/img/{size}/*", handler.NewImageHandler(cache).Serve)
+	}
 
-	r.Get("/api/movies/search/{query}", h.GetMovies)
-	r.Get("/api/tv/search/{query}", h.GetTV)
-	r.Get("/dl/{tracker}", h.DownloadProxy)
-	r.Get("/api/resolve-link", h.ResolveLink)
+	api := handler.APIRoutes(h, tmdbH, magnetH)
+	site := handler.SiteRoutes(h, magnetH, icalH, lookupH)
+	handler.Mount(r, "", site)
+	r.Route(handler.APIPrefix, func(r chi.Router) {
+		r.Use(handler.PreferJSON)
+		handler.Mount(r, "", api)
+	})
+	handler.MountLegacy(r, api)
 
-	r.Get("/apidocs/*", httpSwagger.Handler(
-		httpSwagger.URL(fmt.Sprintf("%s/apidocs/doc.json", cfg.HostURL)),
-	))
+	docs := handler.NewAPIDocsHandler(handler.OpenAPI(api, site), tmpl)
+	r.Get("/apidocs", http.RedirectHandler("/apidocs/", http.StatusMovedPermanently).ServeHTTP)
+	r.Get("/apidocs/", docs.UI)
+	r.Get("/apidocs/openapi.json", docs.Spec)
 
 	addr := ":" + cfg.Port
 	fmt.Printf("OrbitSearch starting on http://localhost%s\n", addr)
@@ -101,3 +98,61 @@ func main() {
 		log.Fatalf("Server failed: %v", err)
 	}
 }
+
+// newMetadataChain puts TMDB first, when configured, followed by the
+// fallbacks named in METADATA_FALLBACKS.
+func newMetadataChain(cfg *config.Config, tmdbClient *tmdb.Client) *metadata.Chain {
+	var providers []metadata.MetadataProvider
+	var ids []tvmaze.IDSource
+	if tmdbClient.Enabled() {
+		providers = append(providers, tmdbClient)
+		ids = append(ids, tmdbClient)
+	} else {
+		log.Printf("TMDB_API_KEY is not set; metadata will come from fallbacks only")
+	}
+
+	var snapshot *metadata.Snapshot
+	for _, name := range cfg.MetadataFallbacks {
+		if name == "snapshot" {
+			s, err := metadata.NewSnapshot(cfg.MetadataSnapshotDir)
+			if err != nil {
+				log.Fatalf("Failed to create metadata snapshot: %v", err)
+			}
+			snapshot = s
+			ids = append(ids, s)
+		}
+	}
+
+	for _, name := range cfg.MetadataFallbacks {
+		switch name {
+		case "snapshot":
+			providers = append(providers, snapshot)
+		case "tvmaze":
+			providers = append(providers, tvmaze.New(ids...))
+		default:
+			log.Fatalf("Unknown metadata fallback %q", name)
+		}
+	}
+
+	chain := metadata.NewChain(providers...)
+	log.Printf("Metadata providers: %v", chain.Names())
+	return chain
+}
+
+// openIMDbDataset loads the dataset written by cmd/imdb-import. A missing
+// file just disables IMDb ratings; a corrupt one is reported and ignored.
+func openIMDbDataset(path string) *imdb.Store {
+	store, err := imdb.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		log.Printf("No IMDb dataset at %s; IMDb ratings disabled", path)
+		return nil
+	}
+	if err != nil {
+		log.Printf("Failed to load IMDb dataset: %v", err)
+		return nil
+	}
+	titles, ratings, episodes := store.Counts()
+	log.Printf("Loaded IMDb dataset from %s: %d titles, %d ratings, %d episodes (imported %s)",
+		path, titles, ratings, episodes, store.Imported().Format("2006-01-02"))
+	return store
+}
@@ -0,0 +1,50 @@
+// Alpine components shared by the movie and TV pages.
+
+function titleCarousel(apiUrl, mediaType, initial) {
+    return {
+        mediaType: mediaType,
+        items: (initial && initial.results) || [],
+        page: (initial && initial.page) || 1,
+        totalPages: (initial && initial.total_pages) || 1,
+        loading: false,
+        async loadMore() {
+            if (this.loading || this.page >= this.totalPages) return;
+            this.loading = true;
+            try {
+                const resp = await fetch(apiUrl + '?page=' + (this.page + 1));
+                const data = await resp.json();
+                if (resp.ok) {
+                    const seen = new Set(this.items.map(i => i.id));
+                    this.items = this.items.concat((data.results || []).filter(i => !seen.has(i.id)));
+                    this.page = data.page || this.page + 1;
+                    this.totalPages = data.total_pages || this.totalPages;
+                } else {
+                    console.error('Carousel failed:', data.error);
+                }
+            } catch (e) { console.error('Carousel fetch failed:', e); }
+            this.loading = false;
+        }
+    }
+}
+
+function reviewSection(apiUrl) {
+    return {
+        reviews: [], page: 1, totalPages: 1, loading: false,
+        init() { this.loadReviews(); },
+        async loadReviews() {
+            this.loading = true;
+            try {
+                const resp = await fetch(apiUrl + '?page=' + this.page);
+                const data = await resp.json();
+                if (resp.ok) {
+                    this.reviews = data.results || [];
+                    this.totalPages = data.total_pages || 1;
+                } else {
+                    console.error('Reviews failed:', data.error);
+                    this.reviews = [];
+                }
+            } catch (e) { console.error('Reviews fetch failed:', e); this.reviews = []; }
+            this.loading = false;
+        }
+    }
+}